@@ -14,6 +14,7 @@ var runCmd = &cobra.Command{
 		if err != nil {
 			panic(err)
 		}
+		defer func() { _ = serv.Log.Close() }()
 
 		err = serv.RunHTTP()
 		if err != nil {
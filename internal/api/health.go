@@ -5,6 +5,7 @@ import (
 
 	"github.com/bitcubix/golang-rest-api/internal/services/health"
 	"github.com/bitcubix/golang-rest-api/pkg/log"
+	"github.com/bitcubix/golang-rest-api/pkg/response"
 )
 
 type HealthEndpoint struct {
@@ -20,5 +21,5 @@ func NewHealthEndpoint(logger log.Logger, service *health.Service) *HealthEndpoi
 }
 
 func (e *HealthEndpoint) GetHealth(w http.ResponseWriter, _ *http.Request) {
-	SendResponse(w, http.StatusOK, Response{"status": e.service.GetStatus()})
+	response.JSON(w, e.logger, http.StatusOK, Response{"status": e.service.GetStatus()})
 }
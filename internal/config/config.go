@@ -18,8 +18,9 @@ type serverConfig struct {
 }
 
 type logConfig struct {
-	Level log.Level
-	File  string
+	Level  log.Level
+	File   string
+	Format log.Format
 }
 
 type databaseConfig struct {
@@ -36,14 +37,20 @@ func Load() *Config {
 		panic(err)
 	}
 
+	logFormat, err := log.ParseFormat(conf.GetString("log.format"))
+	if err != nil {
+		panic(err)
+	}
+
 	return &Config{
 		Server: serverConfig{
 			Host: conf.GetString("server.host"),
 			Port: conf.GetInt("server.port"),
 		},
 		Log: logConfig{
-			Level: logLvl,
-			File:  conf.GetString("log.file"),
+			Level:  logLvl,
+			File:   conf.GetString("log.file"),
+			Format: logFormat,
 		},
 		Database: databaseConfig{
 			Username: conf.GetString("database.username"),
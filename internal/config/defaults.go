@@ -11,6 +11,7 @@ func SetDefaults() {
 	// logConfig
 	conf.SetDefault("log.level", "info")
 	conf.SetDefault("log.file", "./ecdl.log")
+	conf.SetDefault("log.format", "text")
 
 	// databaseConfig
 	conf.SetDefault("database.username", "dbuser")
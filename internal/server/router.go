@@ -1,5 +1,20 @@
 package server
 
+import (
+	"context"
+	"net/http"
+
+	"github.com/bitcubix/golang-rest-api/pkg/middleware"
+)
+
 func (s *Server) setupRouter() {
+	s.Router.Use(middleware.RequestID(s.Log))
+	s.Router.Use(middleware.AccessLog(s.Log.WithPrefix("http.access"), middleware.ExcludePaths("/livez", "/readyz")))
+	s.Router.Use(middleware.Recover(s.Log.WithPrefix("http.recover")))
 
+	s.Health.RegisterReadiness("database", func(ctx context.Context) error {
+		return s.DB.Connection().PingContext(ctx)
+	})
+	s.Router.Handle("/livez", s.Health.LivezHandler()).Methods(http.MethodGet)
+	s.Router.Handle("/readyz", s.Health.ReadyzHandler()).Methods(http.MethodGet)
 }
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"context"
+	stdlog "log"
 	"net/http"
 	"os"
 
@@ -9,8 +11,10 @@ import (
 	"github.com/bitcubix/golang-rest-api/internal/services"
 	"github.com/bitcubix/golang-rest-api/pkg/db"
 	"github.com/bitcubix/golang-rest-api/pkg/errors"
+	"github.com/bitcubix/golang-rest-api/pkg/health"
 	"github.com/bitcubix/golang-rest-api/pkg/log"
 	"github.com/bitcubix/golang-rest-api/pkg/mux"
+	"github.com/bitcubix/golang-rest-api/pkg/server"
 )
 
 type Server struct {
@@ -21,11 +25,12 @@ type Server struct {
 	Router   *mux.Router
 	Services *services.Services
 	API      *api.API
+	Health   *health.Registry
 }
 
 func New() (*Server, error) {
 	config := config.Load()
-	logger := log.New(os.Stderr, config.Log.Level, config.Log.File)
+	logger := log.NewWithFormat(os.Stderr, config.Log.Level, config.Log.File, config.Log.Format)
 
 	database, err := db.New(
 		"mysql",
@@ -39,8 +44,9 @@ func New() (*Server, error) {
 	router := mux.NewRouter()
 
 	httpServer := &http.Server{
-		Handler: router,
-		Addr:    config.Server.GetAddr(),
+		Handler:  router,
+		Addr:     config.Server.GetAddr(),
+		ErrorLog: stdlog.New(log.Writer(logger.WithPrefix("http.server"), log.LevelError), "", 0),
 	}
 
 	server := &Server{
@@ -49,6 +55,7 @@ func New() (*Server, error) {
 		Config: config,
 		DB:     database,
 		Router: router,
+		Health: health.NewRegistry(logger.WithPrefix("health")),
 	}
 
 	server.setupServices()
@@ -58,20 +65,10 @@ func New() (*Server, error) {
 	return server, nil
 }
 
-// Listen starts the http server.
+// RunHTTP starts the http server and shuts it down gracefully on SIGINT or
+// SIGTERM.
 func (s *Server) RunHTTP() error {
-	var err error
-
-	logger := s.Log.WithPrefix("http.server")
-
-	logger.WithFields(log.Fields{"port": s.server.Addr}).Infof("staring http server")
-
-	if err = s.server.ListenAndServe(); err != http.ErrServerClosed {
-		logger.Errorf("failed to start http server: %v", err)
-		return err
-	}
-
-	return nil
+	return server.Serve(context.Background(), s.server, s.Log.WithPrefix("http.server"))
 }
 
-// TODO shutdown, migration
+// TODO migration
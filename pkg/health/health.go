@@ -0,0 +1,182 @@
+// Package health runs liveness and readiness checks of the components of a
+// service and serves their results
+package health
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+	"github.com/bitcubix/golang-rest-api/pkg/response"
+)
+
+// DefaultCheckTimeout is how long a check may run unless the registry sets
+// another timeout
+const DefaultCheckTimeout = 5 * time.Second
+
+// Check reports whether a component is healthy, it returns nil if so
+type Check func(ctx context.Context) error
+
+// Status of a check or of all checks together
+type Status string
+
+const (
+	StatusOK  Status = "ok"
+	StatusErr Status = "error"
+)
+
+// Result is the JSON summary served by the handlers
+type Result struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// CheckResult is the result of a single check
+type CheckResult struct {
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Registry holds the checks of a service
+type Registry struct {
+	logger    log.Logger
+	mu        sync.RWMutex
+	timeout   time.Duration
+	liveness  map[string]Check
+	readiness map[string]Check
+}
+
+// NewRegistry returns an empty registry logging failing checks to l
+func NewRegistry(l log.Logger) *Registry {
+	return &Registry{
+		logger:    l,
+		timeout:   DefaultCheckTimeout,
+		liveness:  make(map[string]Check),
+		readiness: make(map[string]Check),
+	}
+}
+
+// SetTimeout changes how long a single check may run before it counts as
+// failed, DefaultCheckTimeout is used if timeout isn't positive
+func (r *Registry) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.timeout = timeout
+}
+
+// RegisterLiveness adds a check which fails if the process can't recover on
+// its own and should be restarted, replacing a check of the same name
+func (r *Registry) RegisterLiveness(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.liveness[name] = check
+}
+
+// RegisterReadiness adds a check which fails while the service can't handle
+// requests, e.g. because its database is unreachable, replacing a check of the
+// same name
+func (r *Registry) RegisterReadiness(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.readiness[name] = check
+}
+
+// Live runs the liveness checks
+func (r *Registry) Live(ctx context.Context) Result {
+	return r.run(ctx, r.liveness)
+}
+
+// Ready runs the liveness and readiness checks, a service which isn't alive
+// isn't ready either
+func (r *Registry) Ready(ctx context.Context) Result {
+	return r.run(ctx, r.liveness, r.readiness)
+}
+
+// LivezHandler serves the result of the liveness checks, with 200 if all
+// passed and 503 otherwise
+func (r *Registry) LivezHandler() http.Handler {
+	return r.handler(r.Live)
+}
+
+// ReadyzHandler serves the result of the liveness and readiness checks, with
+// 200 if all passed and 503 otherwise
+func (r *Registry) ReadyzHandler() http.Handler {
+	return r.handler(r.Ready)
+}
+
+func (r *Registry) handler(run func(ctx context.Context) Result) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		result := run(req.Context())
+		status := http.StatusOK
+		if result.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+		response.JSON(w, r.logger, status, result)
+	})
+}
+
+// run runs the checks concurrently and logs the failing ones at warn level. A
+// check which doesn't return within the timeout fails with the context error.
+func (r *Registry) run(ctx context.Context, sets ...map[string]Check) Result {
+	r.mu.RLock()
+	timeout := r.timeout
+	checks := make(map[string]Check)
+	for _, set := range sets {
+		for name, check := range set {
+			checks[name] = check
+		}
+	}
+	r.mu.RUnlock()
+
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			// the check may ignore ctx, so it isn't waited for after the timeout
+			done := make(chan error, 1)
+			go func() {
+				done <- check(ctx)
+			}()
+			select {
+			case errs[i] = <-done:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+			}
+		}(i, checks[name])
+	}
+	wg.Wait()
+
+	result := Result{Status: StatusOK, Checks: make(map[string]CheckResult, len(names))}
+	for i, name := range names {
+		if errs[i] == nil {
+			result.Checks[name] = CheckResult{Status: StatusOK}
+			continue
+		}
+		r.logger.WithContext(ctx).WithError(errs[i]).WithFields(log.Fields{"check": name}).Warnf("health check %s failed", name)
+		result.Status = StatusErr
+		result.Checks[name] = CheckResult{Status: StatusErr, Error: errs[i].Error()}
+	}
+	return result
+}
@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+func pass(context.Context) error { return nil }
+
+func fail(context.Context) error { return errors.New("connection refused") }
+
+func serve(t *testing.T, h http.Handler) (int, Result) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var result Result
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON response %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, result
+}
+
+func TestReadyz(t *testing.T) {
+	l, logs := log.NewObserver()
+	r := NewRegistry(l)
+	r.RegisterLiveness("goroutines", pass)
+	r.RegisterReadiness("cache", pass)
+
+	status, result := serve(t, r.ReadyzHandler())
+	if status != http.StatusOK || result.Status != StatusOK {
+		t.Errorf("all checks passing got %d %q, want 200 ok", status, result.Status)
+	}
+	if len(result.Checks) != 2 {
+		t.Errorf("got checks %v, want both", result.Checks)
+	}
+
+	r.RegisterReadiness("db", fail)
+	status, result = serve(t, r.ReadyzHandler())
+	if status != http.StatusServiceUnavailable || result.Status != StatusErr {
+		t.Errorf("failing check got %d %q, want 503 error", status, result.Status)
+	}
+	if got := result.Checks["db"]; got.Status != StatusErr || got.Error != "connection refused" {
+		t.Errorf("result of db = %+v", got)
+	}
+	if got := result.Checks["cache"]; got.Status != StatusOK {
+		t.Errorf("result of cache = %+v, want ok", got)
+	}
+
+	warnings := logs.FilterField("check", "db")
+	if len(warnings) != 1 || warnings[0].Level != log.LevelWarn {
+		t.Errorf("want one warning for the failing check, got %+v", logs.All())
+	}
+
+	// readiness checks don't affect liveness
+	if status, _ := serve(t, r.LivezHandler()); status != http.StatusOK {
+		t.Errorf("livez got %d, want 200", status)
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	l, _ := log.NewObserver()
+	r := NewRegistry(l)
+	r.SetTimeout(20 * time.Millisecond)
+	block := make(chan struct{})
+	defer close(block)
+	r.RegisterReadiness("stuck", func(context.Context) error {
+		<-block
+		return nil
+	})
+	r.RegisterReadiness("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	start := time.Now()
+	result := r.Ready(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Ready took %v despite the timeout", elapsed)
+	}
+	for _, name := range []string{"stuck", "slow"} {
+		if got := result.Checks[name]; got.Error != context.DeadlineExceeded.Error() {
+			t.Errorf("result of %s = %+v, want a deadline error", name, got)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// fatalFlushTimeout is how long a fatal entry waits for the queued entries to
+// be written before it exits
+const fatalFlushTimeout = 5 * time.Second
+
+// OverflowPolicy decides what an async logger does when its buffer is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the caller until there is room in the buffer
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDrop drops the entry and counts it
+	OverflowDrop
+)
+
+// AsyncLogger logger which writes its entries from a background goroutine
+type AsyncLogger struct {
+	Logger
+	handler *asyncHandler
+}
+
+// asyncHandler handler which queues entries for the background goroutine
+type asyncHandler struct {
+	mu      sync.RWMutex
+	closed  bool
+	entries chan asyncEntry
+	done    chan struct{}
+	policy  OverflowPolicy
+	dropped uint64
+}
+
+// asyncEntry is a queued entry. An entry with flushed set is a marker which is
+// closed once every entry queued before it is written.
+type asyncEntry struct {
+	logger  Logger
+	level   Level
+	msg     string
+	flushed chan struct{}
+}
+
+// NewAsync returns a logger which queues entries in a buffer of the given size
+// which is drained by a background goroutine, so slow outputs don't block the
+// caller. Close must be called to write the remaining entries. Fatal and panic
+// entries are written synchronously, fatal ones after waiting a bounded time
+// for the queued entries to be written. Callers reported by WithCaller point to
+// the background goroutine.
+func NewAsync(l Logger, bufferSize int, policy OverflowPolicy) *AsyncLogger {
+	h := &asyncHandler{
+		entries: make(chan asyncEntry, bufferSize),
+		done:    make(chan struct{}),
+		policy:  policy,
+	}
+	go h.run()
+
+	return &AsyncLogger{
+		Logger:  wrap(l, h),
+		handler: h,
+	}
+}
+
+// Dropped returns the number of entries dropped because the buffer was full
+func (a *AsyncLogger) Dropped() uint64 {
+	return atomic.LoadUint64(&a.handler.dropped)
+}
+
+func (h *asyncHandler) handle(l Logger, level Level, msg string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.closed {
+		logAt(l, level, msg)
+		return
+	}
+
+	entry := asyncEntry{logger: l, level: level, msg: msg}
+	if h.policy == OverflowDrop {
+		select {
+		case h.entries <- entry:
+		default:
+			atomic.AddUint64(&h.dropped, 1)
+		}
+		return
+	}
+	h.entries <- entry
+}
+
+// run writes the queued entries until the queue is closed
+func (h *asyncHandler) run() {
+	defer close(h.done)
+	for entry := range h.entries {
+		if entry.flushed != nil {
+			close(entry.flushed)
+			continue
+		}
+		logAt(entry.logger, entry.level, entry.msg)
+	}
+}
+
+// flush waits until the entries queued so far are written, at most
+// fatalFlushTimeout
+func (h *asyncHandler) flush() {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.closed {
+		return
+	}
+	timeout := time.NewTimer(fatalFlushTimeout)
+	defer timeout.Stop()
+
+	flushed := make(chan struct{})
+	select {
+	case h.entries <- asyncEntry{flushed: flushed}:
+	case <-timeout.C:
+		return
+	}
+	select {
+	case <-flushed:
+	case <-timeout.C:
+	}
+}
+
+// Close stops accepting entries and waits until the queued ones are written,
+// entries logged afterwards are written synchronously
+func (h *asyncHandler) Close() error {
+	h.mu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.entries)
+	}
+	h.mu.Unlock()
+
+	<-h.done
+	return nil
+}
@@ -0,0 +1,94 @@
+package log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowWriter discards writes after a delay, like a congested network output
+type slowWriter struct {
+	delay time.Duration
+}
+
+func (w slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return len(p), nil
+}
+
+// lockedBuffer buffer which can be written and read concurrently
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	time.Sleep(time.Millisecond)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestAsyncFatalFlushesQueue(t *testing.T) {
+	var out lockedBuffer
+	var atExit string
+	l := NewAsync(NewWithOptions(WithOutput(&out), WithColors(false), WithExitFunc(func(int) {
+		atExit = out.String()
+	})), 64, OverflowBlock)
+	defer l.Close()
+
+	for i := 0; i < 20; i++ {
+		l.Infof("entry %d", i)
+	}
+	l.Fatal("giving up")
+
+	lines := strings.Split(strings.TrimSuffix(atExit, "\n"), "\n")
+	if len(lines) != 21 {
+		t.Fatalf("%d lines written before exiting, want 21:\n%s", len(lines), atExit)
+	}
+	if !strings.Contains(lines[19], "entry 19") || !strings.Contains(lines[20], "giving up") {
+		t.Errorf("fatal entry isn't written after the queued ones:\n%s", atExit)
+	}
+}
+
+func benchmarkLog(b *testing.B, l Logger) {
+	l = l.WithFields(Fields{"service": "users", "version": "1.2.3"})
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Infof("request %s handled", "GET /users")
+		}
+	})
+}
+
+func BenchmarkSync(b *testing.B) {
+	benchmarkLog(b, NewWithOptions(WithOutput(ioutil.Discard), WithFormat(FormatJSON)))
+}
+
+func BenchmarkAsync(b *testing.B) {
+	l := NewAsync(NewWithOptions(WithOutput(ioutil.Discard), WithFormat(FormatJSON)), 1024, OverflowBlock)
+	benchmarkLog(b, l)
+	_ = l.Close()
+}
+
+func BenchmarkSyncSlowOutput(b *testing.B) {
+	benchmarkLog(b, NewWithOptions(WithOutput(slowWriter{delay: time.Microsecond}), WithFormat(FormatJSON)))
+}
+
+func BenchmarkAsyncSlowOutput(b *testing.B) {
+	l := NewAsync(NewWithOptions(WithOutput(slowWriter{delay: time.Microsecond}), WithFormat(FormatJSON)), 1024, OverflowDrop)
+	benchmarkLog(b, l)
+	b.StopTimer()
+	_ = l.Close()
+	b.ReportMetric(float64(l.Dropped())/float64(b.N), "dropped/op")
+}
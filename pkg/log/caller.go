@@ -0,0 +1,121 @@
+package log
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maximumCallerDepth limits how many frames are inspected to find the caller
+const maximumCallerDepth = 32
+
+var (
+	logPackage    = reflect.TypeOf(logrusLogger{}).PkgPath()
+	logrusPackage = reflect.TypeOf(logrus.Logger{}).PkgPath()
+)
+
+// callerHook hook for logrus which replaces the caller found by logrus, which
+// would point into this package, with the actual call site
+type callerHook struct {
+	withFunction bool
+}
+
+// Fire func used by logrus to set the caller of the entry
+func (hook *callerHook) Fire(entry *logrus.Entry) error {
+	frame := findCaller()
+	if frame != nil && !hook.withFunction {
+		frame.Function = ""
+	}
+	entry.Caller = frame
+	return nil
+}
+
+// Levels defines in which log levels the caller hook works
+func (hook *callerHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// findCaller returns the first frame on the stack outside of this package and
+// logrus
+func findCaller() *runtime.Frame {
+	pcs := make([]uintptr, maximumCallerDepth)
+	depth := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:depth])
+
+	for {
+		f, more := frames.Next()
+		pkg := getPackageName(f.Function)
+		if pkg != logPackage && pkg != logrusPackage {
+			return &f
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// getPackageName reduces a fully qualified function name to the package name
+func getPackageName(f string) string {
+	for {
+		lastPeriod := strings.LastIndex(f, ".")
+		lastSlash := strings.LastIndex(f, "/")
+		if lastPeriod > lastSlash {
+			f = f[:lastPeriod]
+		} else {
+			break
+		}
+	}
+	return f
+}
+
+// StackKey field the stack trace is logged under by WithStackTrace
+const StackKey = "stack"
+
+// stackHook hook for logrus which attaches the stack trace of the call site to
+// error, fatal and panic entries
+type stackHook struct{}
+
+// Fire func used by logrus to add the stack trace to the entry. The field map
+// is shared with the logger the entry was created from, so it is replaced
+// instead of modified.
+func (hook *stackHook) Fire(entry *logrus.Entry) error {
+	data := make(logrus.Fields, len(entry.Data)+1)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	data[StackKey] = stackTrace()
+	entry.Data = data
+	return nil
+}
+
+// Levels defines in which log levels the stack hook works
+func (hook *stackHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+}
+
+// stackTrace returns the stack starting at the first frame outside of this
+// package and logrus
+func stackTrace() string {
+	pcs := make([]uintptr, maximumCallerDepth)
+	depth := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:depth])
+
+	var b strings.Builder
+	inside := true
+	for {
+		f, more := frames.Next()
+		if inside {
+			pkg := getPackageName(f.Function)
+			inside = pkg == logPackage || pkg == logrusPackage
+		}
+		if !inside {
+			_, _ = fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		}
+		if !more {
+			return b.String()
+		}
+	}
+}
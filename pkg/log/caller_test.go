@@ -0,0 +1,62 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// TestCallerIsCallSite lives in an external test package as the caller is
+// looked up outside of the log package
+func TestCallerIsCallSite(t *testing.T) {
+	var buf bytes.Buffer
+	l := log.NewWithOptions(log.WithOutput(&buf), log.WithFormat(log.FormatJSON), log.WithCaller(true))
+
+	l.WithPrefix("api").WithFields(log.Fields{"user": "alice"}).Infof("login %s", "succeeded")
+	_, file, line, _ := runtime.Caller(0)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", buf.String(), err)
+	}
+	if want := fmt.Sprintf("%s:%d", file, line-1); entry["file"] != want {
+		t.Errorf("file = %v, want %s", entry["file"], want)
+	}
+	if entry["func"] != "github.com/bitcubix/golang-rest-api/pkg/log_test.TestCallerIsCallSite" {
+		t.Errorf("func = %v, want the test function", entry["func"])
+	}
+}
+
+func TestStackTraceOnlyOnErrors(t *testing.T) {
+	var buf bytes.Buffer
+	l := log.NewWithOptions(log.WithOutput(&buf), log.WithFormat(log.FormatJSON), log.WithStackTrace())
+
+	l.Info("started")
+	l.Error("failed")
+
+	dec := json.NewDecoder(&buf)
+	var info, failure map[string]interface{}
+	if err := dec.Decode(&info); err != nil {
+		t.Fatalf("invalid info entry: %v", err)
+	}
+	if err := dec.Decode(&failure); err != nil {
+		t.Fatalf("invalid error entry: %v", err)
+	}
+
+	if _, ok := info[log.StackKey]; ok {
+		t.Errorf("info entry has a stack trace: %v", info[log.StackKey])
+	}
+	stack, _ := failure[log.StackKey].(string)
+	if !strings.HasPrefix(stack, "github.com/bitcubix/golang-rest-api/pkg/log_test.TestStackTraceOnlyOnErrors\n") {
+		t.Errorf("stack trace doesn't start at the call site:\n%s", stack)
+	}
+	// the outermost frame of the goroutine must not be dropped
+	if lines := strings.Split(strings.TrimSpace(stack), "\n"); len(lines) < 2 || lines[len(lines)-2] != "runtime.goexit" {
+		t.Errorf("stack trace lacks the outermost frame:\n%s", stack)
+	}
+}
@@ -0,0 +1,52 @@
+package log
+
+import (
+	"encoding/json"
+
+	"github.com/sirupsen/logrus"
+)
+
+// cloudWatchFormatter renders entries as flat JSON which CloudWatch Logs
+// Insights parses without custom parsers
+type cloudWatchFormatter struct {
+	// LevelKey is the key the level is logged under, "level" if empty.
+	LevelKey string
+	// Expand nested maps and structs into dotted keys up to this depth.
+	FlattenDepth int
+}
+
+// Format func used by logrus to format the log. The timestamp is logged in
+// milliseconds since the epoch as @timestamp.
+func (f *cloudWatchFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	entry = flattenGroups(entry, f.FlattenDepth)
+	entry = renameClashes(entry)
+	doc := make(map[string]interface{}, len(entry.Data)+3)
+
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		doc[k] = v
+	}
+
+	levelKey := f.LevelKey
+	if levelKey == "" {
+		levelKey = "level"
+	}
+	doc["@timestamp"] = entry.Time.UnixNano() / 1e6
+	doc["msg"] = entry.Message
+	doc[levelKey] = string(fromLogrusLevel(entry.Level))
+	if entry.HasCaller() {
+		function, file := callerPrettyfier(entry.Caller)
+		if function != "" {
+			doc["func"] = function
+		}
+		doc["file"] = file
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
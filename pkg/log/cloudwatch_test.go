@@ -0,0 +1,27 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestCloudWatchEpochMillis(t *testing.T) {
+	f := &cloudWatchFormatter{LevelKey: "severity"}
+	entry := &logrus.Entry{
+		Time:    time.Date(2024, 3, 1, 12, 0, 0, 123456789, time.UTC),
+		Level:   logrus.WarnLevel,
+		Message: "slow query",
+		Data:    logrus.Fields{"table": "users"},
+	}
+
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	want := `{"@timestamp":1709294400123,"msg":"slow query","severity":"warn","table":"users"}` + "\n"
+	if string(b) != want {
+		t.Errorf("got  %s\nwant %s", b, want)
+	}
+}
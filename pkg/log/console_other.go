@@ -0,0 +1,13 @@
+//go:build !windows
+
+package log
+
+import (
+	"io"
+)
+
+// enableVirtualTerminal reports whether the terminal supports ANSI escape
+// codes, which every terminal outside of Windows does
+func enableVirtualTerminal(w io.Writer) bool {
+	return true
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+
+package log
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestColorsOutsideWindows(t *testing.T) {
+	if !enableVirtualTerminal(os.Stdout) {
+		t.Error("enableVirtualTerminal reported no ANSI support outside Windows")
+	}
+
+	var colored, plain bytes.Buffer
+	NewWithOptions(WithOutput(&colored), WithColors(true)).Info("colored")
+	NewWithOptions(WithOutput(&plain), WithColors(false)).Info("plain")
+
+	if !bytes.Contains(colored.Bytes(), []byte("\x1b[")) {
+		t.Errorf("forced colors were disabled: %q", colored.Bytes())
+	}
+	if bytes.Contains(plain.Bytes(), []byte("\x1b[")) {
+		t.Errorf("disabled colors were written: %q", plain.Bytes())
+	}
+}
@@ -0,0 +1,186 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// ctxKey unexported type for values the package stores in a context
+type ctxKey int
+
+const (
+	loggerKey ctxKey = iota
+	levelKey
+	bagKey
+)
+
+// ContextKey type for well known values read from a context by WithContext
+type ContextKey string
+
+const (
+	// RequestIDKey context key of the request id
+	RequestIDKey ContextKey = "request_id"
+	// TraceIDKey context key of the trace id
+	TraceIDKey ContextKey = "trace_id"
+	// UserIDKey context key of the user id
+	UserIDKey ContextKey = "user_id"
+)
+
+// ContextField maps a context key to the field name its value is logged under
+type ContextField struct {
+	Key  interface{}
+	Name string
+}
+
+// DefaultContextFields are the fields WithContext reads unless configured
+// otherwise through WithContextFields
+var DefaultContextFields = []ContextField{
+	{Key: RequestIDKey, Name: "request_id"},
+	{Key: TraceIDKey, Name: "trace_id"},
+	{Key: UserIDKey, Name: "user_id"},
+}
+
+var (
+	defaultLogger     Logger
+	defaultLoggerOnce sync.Once
+)
+
+// NewContext returns a copy of ctx which carries the given logger. Fields can
+// be added to it later on through AddField.
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(withFieldBag(ctx), loggerKey, l)
+}
+
+// RequestIDFromContext returns the request id stored in ctx under
+// RequestIDKey, e.g. by middleware.RequestID
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(RequestIDKey).(string)
+	return id
+}
+
+// FromContext returns the logger stored in ctx by NewContext, if there is none
+// a default logger writing info entries to stderr is returned. If ctx carries
+// a level set by ContextWithLevel or fields added through AddField the logger
+// is bound to ctx, so it logs at that level with those fields.
+func FromContext(ctx context.Context) Logger {
+	l := getDefaultLogger()
+	if ctx == nil {
+		return l
+	}
+	if stored, ok := ctx.Value(loggerKey).(Logger); ok && stored != nil {
+		l = stored
+	}
+	if _, ok := LevelFromContext(ctx); ok {
+		return l.WithContext(ctx)
+	}
+	if bag := bagOf(ctx); bag != nil && len(bag.snapshot()) > 0 {
+		return l.WithContext(ctx)
+	}
+	return l
+}
+
+// ContextWithLevel returns a copy of ctx which makes loggers bound to it
+// through WithContext log at the given level, instead of their global or
+// prefix level. It is meant to raise the verbosity of single requests.
+func ContextWithLevel(ctx context.Context, level Level) context.Context {
+	return context.WithValue(ctx, levelKey, level)
+}
+
+// LevelFromContext returns the level stored in ctx by ContextWithLevel
+func LevelFromContext(ctx context.Context) (Level, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	level, ok := ctx.Value(levelKey).(Level)
+	return level, ok
+}
+
+// getDefaultLogger lazily creates the logger used when a context carries none
+func getDefaultLogger() Logger {
+	defaultLoggerOnce.Do(func() {
+		defaultLogger = New(os.Stderr, LevelInfo, "")
+	})
+	return defaultLogger
+}
+
+// ContextExtractor returns fields derived from a context, e.g. the ids of the
+// active trace
+type ContextExtractor func(ctx context.Context) Fields
+
+// contextFields returns the fields found in ctx for the given context fields
+// and extractors and the fields added through AddField, keys without a value
+// are skipped
+func contextFields(ctx context.Context, fields []ContextField, extractors []ContextExtractor) Fields {
+	found := Fields{}
+	if ctx == nil {
+		return found
+	}
+	for _, field := range fields {
+		if value := ctx.Value(field.Key); value != nil {
+			found[field.Name] = value
+		}
+	}
+	if bag := bagOf(ctx); bag != nil {
+		for k, v := range bag.snapshot() {
+			found[k] = v
+		}
+	}
+	for _, extract := range extractors {
+		for k, v := range extract(ctx) {
+			found[k] = v
+		}
+	}
+	return found
+}
+
+// Field keys of LogContextDone
+const (
+	ContextErrorKey = "context_error"
+	ContextCauseKey = "context_cause"
+	DeadlineKey     = "deadline"
+	OverdueKey      = "overdue"
+)
+
+// LogContextDone logs at warn level that ctx is done, if it is, and reports
+// whether it was. The entry carries the context error, telling
+// context.Canceled and context.DeadlineExceeded apart, the cause if one was
+// given and, for contexts with a deadline, the deadline and how long it has
+// passed. Handlers call it when giving up on a request:
+//
+//	if err := db.QueryContext(ctx, query); err != nil {
+//		if log.LogContextDone(ctx, l) {
+//			return
+//		}
+//		...
+//	}
+func LogContextDone(ctx context.Context, l Logger) bool {
+	err := ctx.Err()
+	if err == nil {
+		return false
+	}
+
+	fields := Fields{ContextErrorKey: err.Error()}
+	if cause := context.Cause(ctx); cause != nil && cause != err {
+		fields[ContextCauseKey] = cause.Error()
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		fields[DeadlineKey] = deadline.Round(0)
+		if overdue := time.Since(deadline); overdue > 0 {
+			fields[OverdueKey] = overdue
+		}
+	}
+
+	logger := l.WithContext(ctx).WithFields(fields)
+	if errors.Is(err, context.DeadlineExceeded) {
+		logger.Warn("context deadline exceeded")
+	} else {
+		logger.Warn("context canceled")
+	}
+	return true
+}
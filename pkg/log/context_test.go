@@ -0,0 +1,68 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLogContextDoneActive(t *testing.T) {
+	l, logs := NewObserver()
+
+	if LogContextDone(context.Background(), l) {
+		t.Errorf("LogContextDone reported an active context as done")
+	}
+	if logs.Len() != 0 {
+		t.Errorf("logged %d entries for an active context, want 0", logs.Len())
+	}
+}
+
+func TestLogContextDoneCanceled(t *testing.T) {
+	l, logs := NewObserver()
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("client went away"))
+
+	if !LogContextDone(ctx, l) {
+		t.Fatalf("LogContextDone reported a canceled context as active")
+	}
+	entries := logs.FilterLevel(LevelWarn)
+	if len(entries) != 1 {
+		t.Fatalf("logged %d warnings, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Message != "context canceled" || e.Fields[ContextErrorKey] != context.Canceled.Error() {
+		t.Errorf("got %q with fields %v", e.Message, e.Fields)
+	}
+	if e.Fields[ContextCauseKey] != "client went away" {
+		t.Errorf("cause = %v, want client went away", e.Fields[ContextCauseKey])
+	}
+	if _, ok := e.Fields[DeadlineKey]; ok {
+		t.Errorf("context without deadline logged a deadline: %v", e.Fields)
+	}
+}
+
+func TestLogContextDoneDeadlineExceeded(t *testing.T) {
+	l, logs := NewObserver()
+	deadline := time.Now().Add(-time.Second)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	if !LogContextDone(ctx, l) {
+		t.Fatalf("LogContextDone reported an expired context as active")
+	}
+	entries := logs.FilterLevel(LevelWarn)
+	if len(entries) != 1 {
+		t.Fatalf("logged %d warnings, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Message != "context deadline exceeded" || e.Fields[ContextErrorKey] != context.DeadlineExceeded.Error() {
+		t.Errorf("got %q with fields %v", e.Message, e.Fields)
+	}
+	if got, _ := e.Fields[DeadlineKey].(time.Time); !got.Equal(deadline) {
+		t.Errorf("deadline = %v, want %v", e.Fields[DeadlineKey], deadline)
+	}
+	if overdue, _ := e.Fields[OverdueKey].(time.Duration); overdue < time.Second {
+		t.Errorf("overdue = %v, want at least 1s", e.Fields[OverdueKey])
+	}
+}
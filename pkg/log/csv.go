@@ -0,0 +1,113 @@
+package log
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// csvFieldsColumn is the trailing column fields without own column are packed
+// into
+const csvFieldsColumn = "fields"
+
+// csvFormatter renders entries as CSV or TSV rows, for loading logs into a
+// spreadsheet or data frame
+type csvFormatter struct {
+	// Comma separates the columns, ',' if zero.
+	Comma rune
+	// TimestampFormat to use for display when a full timestamp is printed.
+	TimestampFormat string
+	// Columns are the fields written after time, level and msg, in order. A
+	// field missing in an entry leaves its column empty.
+	Columns []string
+	// PackFields writes the fields without own column as JSON object into a
+	// trailing column, they are dropped otherwise.
+	PackFields bool
+}
+
+// CSVHeader returns the header row matching the rows of the CSV format with
+// the given columns, e.g. to start a file with
+func CSVHeader(columns []string, packFields bool) []string {
+	header := append([]string{"time", "level", "msg"}, columns...)
+	if packFields {
+		header = append(header, csvFieldsColumn)
+	}
+	return header
+}
+
+// Format func used by logrus to format the log. The columns are time, level
+// and msg followed by the configured field columns, values are quoted as
+// RFC 4180 requires.
+func (f *csvFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	row := make([]string, 0, len(f.Columns)+4)
+	row = append(row,
+		entry.Time.Format(f.TimestampFormat),
+		string(fromLogrusLevel(entry.Level)),
+		entry.Message,
+	)
+	for _, column := range f.Columns {
+		value, ok := entry.Data[column]
+		if !ok {
+			row = append(row, "")
+			continue
+		}
+		row = append(row, csvValue(value))
+	}
+
+	if f.PackFields {
+		rest := make(map[string]interface{})
+		for k, v := range entry.Data {
+			if !f.hasColumn(k) {
+				if err, ok := v.(error); ok {
+					v = err.Error()
+				}
+				rest[k] = v
+			}
+		}
+		packed := ""
+		if len(rest) > 0 {
+			b, err := json.Marshal(rest)
+			if err != nil {
+				return nil, err
+			}
+			packed = string(b)
+		}
+		row = append(row, packed)
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if f.Comma != 0 {
+		w.Comma = f.Comma
+	}
+	if err := w.Write(row); err != nil {
+		return nil, err
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func (f *csvFormatter) hasColumn(key string) bool {
+	for _, column := range f.Columns {
+		if column == key {
+			return true
+		}
+	}
+	return false
+}
+
+// csvValue renders a field value for a CSV cell
+func csvValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(v)
+}
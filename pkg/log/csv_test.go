@@ -0,0 +1,57 @@
+package log
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestCSVQuoting(t *testing.T) {
+	f := &csvFormatter{TimestampFormat: time.RFC3339, Columns: []string{"user", "missing"}, PackFields: true}
+	entry := &logrus.Entry{
+		Time:    time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Level:   logrus.WarnLevel,
+		Message: `login failed for "alice", retrying`,
+		Data:    logrus.Fields{"user": "alice, admin", "attempt": 2},
+	}
+
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	want := `2024-03-01T12:00:00Z,warn,"login failed for ""alice"", retrying","alice, admin",,"{""attempt"":2}"` + "\n"
+	if string(b) != want {
+		t.Errorf("got  %s\nwant %s", b, want)
+	}
+
+	record, err := csv.NewReader(strings.NewReader(string(b))).Read()
+	if err != nil {
+		t.Fatalf("row doesn't parse as CSV: %v", err)
+	}
+	if record[2] != entry.Message || record[3] != "alice, admin" {
+		t.Errorf("parsed row %q lost the quoted values", record)
+	}
+	if len(record) != len(CSVHeader(f.Columns, true)) {
+		t.Errorf("row has %d columns, header %d", len(record), len(CSVHeader(f.Columns, true)))
+	}
+}
+
+func TestTSV(t *testing.T) {
+	f := &csvFormatter{Comma: '\t', TimestampFormat: time.RFC3339}
+	entry := &logrus.Entry{
+		Time:    time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Level:   logrus.InfoLevel,
+		Message: "a, b\tc",
+	}
+
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if want := "2024-03-01T12:00:00Z\tinfo\t\"a, b\tc\"\n"; string(b) != want {
+		t.Errorf("got %q, want %q", b, want)
+	}
+}
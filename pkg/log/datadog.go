@@ -0,0 +1,101 @@
+package log
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// datadogFormatter renders entries as JSON with the attribute names Datadog
+// recognizes
+type datadogFormatter struct {
+	// Service, Env and Version are logged for unified service tagging if set.
+	Service string
+	Env     string
+	Version string
+}
+
+// Format func used by logrus to format the log. Custom fields with dotted keys,
+// like dd.trace_id, are nested into objects.
+func (f *datadogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	doc := make(map[string]interface{}, len(entry.Data)+6)
+
+	// sorted, so a key colliding with the path of a dotted key always ends up
+	// at the same place
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := entry.Data[k]
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		switch k {
+		case ErrorKey:
+			setNested(doc, "error.message", v)
+		case StackKey:
+			setNested(doc, "error.stack", v)
+		case "prefix":
+			setNested(doc, "logger.name", v)
+		default:
+			setNested(doc, k, v)
+		}
+	}
+
+	doc["timestamp"] = entry.Time.UTC().Format(time.RFC3339Nano)
+	doc["message"] = entry.Message
+	doc["status"] = datadogStatus(entry.Level)
+
+	var tags []string
+	if f.Service != "" {
+		doc["service"] = f.Service
+	}
+	if f.Env != "" {
+		doc["env"] = f.Env
+		tags = append(tags, "env:"+f.Env)
+	}
+	if f.Version != "" {
+		doc["version"] = f.Version
+		tags = append(tags, "version:"+f.Version)
+	}
+	if len(tags) > 0 {
+		doc["ddtags"] = strings.Join(tags, ",")
+	}
+	if entry.HasCaller() {
+		setNested(doc, "logger.file_name", entry.Caller.File)
+		setNested(doc, "logger.line", entry.Caller.Line)
+		if entry.Caller.Function != "" {
+			setNested(doc, "logger.method_name", entry.Caller.Function)
+		}
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// datadogStatus maps a logrus level to the matching Datadog status
+func datadogStatus(level logrus.Level) string {
+	switch level {
+	case logrus.PanicLevel:
+		return "emergency"
+	case logrus.FatalLevel:
+		return "critical"
+	case logrus.ErrorLevel:
+		return "error"
+	case logrus.WarnLevel:
+		return "warn"
+	case logrus.InfoLevel:
+		return "info"
+	default:
+		return "debug"
+	}
+}
@@ -0,0 +1,61 @@
+package log
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestDatadogCollidingKeysDeterministic(t *testing.T) {
+	f := &datadogFormatter{Service: "users", Env: "prod"}
+	entry := &logrus.Entry{
+		Time:    time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Level:   logrus.WarnLevel,
+		Message: "collision",
+		Data:    logrus.Fields{"dd": "plain", "dd.trace_id": "123", "prefix": "db"},
+	}
+
+	want := `{"dd":"plain","dd.trace_id":"123","ddtags":"env:prod","env":"prod","logger":{"name":"db"},` +
+		`"message":"collision","service":"users","status":"warn","timestamp":"2024-03-01T12:00:00Z"}` + "\n"
+	for i := 0; i < 20; i++ {
+		b, err := f.Format(entry)
+		if err != nil {
+			t.Fatalf("Format failed: %v", err)
+		}
+		if string(b) != want {
+			t.Fatalf("got  %s\nwant %s", b, want)
+		}
+	}
+}
+
+func TestDatadogKeyMapping(t *testing.T) {
+	f := &datadogFormatter{Service: "users", Env: "prod", Version: "1.2.0"}
+	entry := &logrus.Entry{
+		Logger:  &logrus.Logger{ReportCaller: true},
+		Time:    time.Date(2024, 3, 1, 14, 0, 0, 500000000, time.FixedZone("CET", 2*60*60)),
+		Level:   logrus.FatalLevel,
+		Message: "shutting down",
+		Data: logrus.Fields{
+			ErrorKey:      errors.New("disk full"),
+			StackKey:      "main.main()",
+			"prefix":      "db",
+			"dd.trace_id": "123",
+		},
+		Caller: &runtime.Frame{File: "/app/db.go", Line: 42, Function: "app.Query"},
+	}
+
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	want := `{"dd":{"trace_id":"123"},"ddtags":"env:prod,version:1.2.0","env":"prod",` +
+		`"error":{"message":"disk full","stack":"main.main()"},` +
+		`"logger":{"file_name":"/app/db.go","line":42,"method_name":"app.Query","name":"db"},` +
+		`"message":"shutting down","service":"users","status":"critical","timestamp":"2024-03-01T12:00:00.5Z","version":"1.2.0"}` + "\n"
+	if string(b) != want {
+		t.Errorf("got  %s\nwant %s", b, want)
+	}
+}
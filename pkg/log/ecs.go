@@ -0,0 +1,91 @@
+package log
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ecsVersion version of the Elastic Common Schema the formatter follows
+const ecsVersion = "1.6.0"
+
+// ecsFormatter renders entries as JSON following the Elastic Common Schema
+type ecsFormatter struct {
+	// ServiceName is logged as service.name if set.
+	ServiceName string
+}
+
+// Format func used by logrus to format the log. Standard fields are mapped to
+// their ECS names, custom fields with dotted keys are nested into objects.
+func (f *ecsFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	doc := make(map[string]interface{}, len(entry.Data)+4)
+
+	// sorted, so a key colliding with the path of a dotted key always ends up
+	// at the same place
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := entry.Data[k]
+		switch k {
+		case ErrorKey:
+			if err, ok := v.(error); ok {
+				v = err.Error()
+			}
+			setNested(doc, "error.message", v)
+		case "prefix":
+			setNested(doc, "log.logger", v)
+		default:
+			if err, ok := v.(error); ok {
+				v = err.Error()
+			}
+			setNested(doc, k, v)
+		}
+	}
+
+	doc["@timestamp"] = entry.Time.UTC().Format(time.RFC3339Nano)
+	doc["message"] = entry.Message
+	setNested(doc, "log.level", string(fromLogrusLevel(entry.Level)))
+	setNested(doc, "ecs.version", ecsVersion)
+	if f.ServiceName != "" {
+		setNested(doc, "service.name", f.ServiceName)
+	}
+	if entry.HasCaller() {
+		setNested(doc, "log.origin.file.name", entry.Caller.File)
+		setNested(doc, "log.origin.file.line", entry.Caller.Line)
+		if entry.Caller.Function != "" {
+			setNested(doc, "log.origin.function", entry.Caller.Function)
+		}
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// setNested sets the value at the dotted key path, creating nested objects on
+// the way. A value already set on the path is kept under the key itself.
+func setNested(doc map[string]interface{}, key string, value interface{}) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := doc[part].(map[string]interface{})
+		if !ok {
+			if _, taken := doc[part]; taken {
+				doc[key] = value
+				return
+			}
+			child = make(map[string]interface{})
+			doc[part] = child
+		}
+		doc = child
+	}
+	doc[parts[len(parts)-1]] = value
+}
@@ -0,0 +1,55 @@
+package log
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestECSCollidingKeysDeterministic(t *testing.T) {
+	f := &ecsFormatter{ServiceName: "users"}
+	entry := &logrus.Entry{
+		Time:    time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Level:   logrus.InfoLevel,
+		Message: "collision",
+		Data:    logrus.Fields{"http": "plain", "http.method": "GET", "user.id": 7, "user.name": "alice"},
+	}
+
+	want := `{"@timestamp":"2024-03-01T12:00:00Z","ecs":{"version":"1.6.0"},"http":"plain","http.method":"GET",` +
+		`"log":{"level":"info"},"message":"collision","service":{"name":"users"},"user":{"id":7,"name":"alice"}}` + "\n"
+	for i := 0; i < 20; i++ {
+		b, err := f.Format(entry)
+		if err != nil {
+			t.Fatalf("Format failed: %v", err)
+		}
+		if string(b) != want {
+			t.Fatalf("got  %s\nwant %s", b, want)
+		}
+	}
+}
+
+func TestECSKeyMapping(t *testing.T) {
+	f := &ecsFormatter{ServiceName: "users"}
+	entry := &logrus.Entry{
+		Logger:  &logrus.Logger{ReportCaller: true},
+		Time:    time.Date(2024, 3, 1, 14, 0, 0, 500000000, time.FixedZone("CET", 2*60*60)),
+		Level:   logrus.ErrorLevel,
+		Message: "query failed",
+		Data:    logrus.Fields{ErrorKey: errors.New("timeout"), "prefix": "db", "table": "users"},
+		Caller:  &runtime.Frame{File: "/app/db.go", Line: 42, Function: "app.Query"},
+	}
+
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	want := `{"@timestamp":"2024-03-01T12:00:00.5Z","ecs":{"version":"1.6.0"},"error":{"message":"timeout"},` +
+		`"log":{"level":"error","logger":"db","origin":{"file":{"line":42,"name":"/app/db.go"},"function":"app.Query"}},` +
+		`"message":"query failed","service":{"name":"users"},"table":"users"}` + "\n"
+	if string(b) != want {
+		t.Errorf("got  %s\nwant %s", b, want)
+	}
+}
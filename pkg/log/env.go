@@ -0,0 +1,63 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const (
+	// EnvLevel minimum level of entries, defaults to "info"
+	EnvLevel = "LOG_LEVEL"
+	// EnvFormat output format as accepted by ParseFormat, defaults to "text"
+	EnvFormat = "LOG_FORMAT"
+	// EnvFile file entries are additionally written to, unset by default
+	EnvFile = "LOG_FILE"
+	// EnvColor forces colored text output on or off, by default colors are
+	// used if stderr is a terminal and EnvNoColor is not set
+	EnvColor = "LOG_COLOR"
+	// EnvNoColor disables colors when set to any value, see https://no-color.org
+	EnvNoColor = "NO_COLOR"
+)
+
+// NewFromEnv returns a logger writing to stderr which is configured by the
+// EnvLevel, EnvFormat, EnvFile and EnvColor environment variables. Invalid
+// values fall back to the default and are reported as warning.
+func NewFromEnv(opts ...Option) Logger {
+	var warnings []string
+	var envOpts []Option
+
+	if value := os.Getenv(EnvLevel); value != "" {
+		if level, err := ParseLevel(value); err == nil {
+			envOpts = append(envOpts, WithLevel(level))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("failed to parse %s '%s', defaulting to '%s'", EnvLevel, value, LevelInfo))
+		}
+	}
+
+	if value := os.Getenv(EnvFormat); value != "" {
+		if format, err := ParseFormat(value); err == nil {
+			envOpts = append(envOpts, WithFormat(format))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("failed to parse %s '%s', defaulting to '%s'", EnvFormat, value, FormatText))
+		}
+	}
+
+	if value := os.Getenv(EnvFile); value != "" {
+		envOpts = append(envOpts, WithFile(value))
+	}
+
+	if value := os.Getenv(EnvColor); value != "" {
+		if colors, err := strconv.ParseBool(value); err == nil {
+			envOpts = append(envOpts, WithColors(colors))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("failed to parse %s '%s', ignoring it", EnvColor, value))
+		}
+	}
+
+	logger := NewWithOptions(append(envOpts, opts...)...)
+	for _, warning := range warnings {
+		logger.Warnf("%s", warning)
+	}
+	return logger
+}
@@ -0,0 +1,45 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv(EnvLevel, "debug")
+	t.Setenv(EnvFormat, "json")
+
+	var buf bytes.Buffer
+	l := NewFromEnv(WithOutput(&buf))
+
+	if got := l.Level(); got != LevelDebug {
+		t.Errorf("level = %q, want %q", got, LevelDebug)
+	}
+	l.Debug("started")
+	if !strings.HasPrefix(buf.String(), "{") {
+		t.Errorf("output isn't JSON: %q", buf.String())
+	}
+}
+
+func TestNewFromEnvGarbage(t *testing.T) {
+	t.Setenv(EnvLevel, "loud")
+	t.Setenv(EnvFormat, "xml")
+	t.Setenv(EnvColor, "maybe")
+
+	var buf bytes.Buffer
+	l := NewFromEnv(WithOutput(&buf), WithColors(false))
+
+	if got := l.Level(); got != LevelInfo {
+		t.Errorf("level = %q, want the default %q", got, LevelInfo)
+	}
+	out := buf.String()
+	for _, want := range []string{"LOG_LEVEL 'loud'", "LOG_FORMAT 'xml'", "LOG_COLOR 'maybe'"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("no warning about %s: %q", want, out)
+		}
+	}
+	if strings.Count(out, "WARN") != 3 {
+		t.Errorf("want 3 warnings in text format, got %q", out)
+	}
+}
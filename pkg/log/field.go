@@ -0,0 +1,132 @@
+package log
+
+import (
+	"math"
+	"time"
+)
+
+// FieldType tells how the value of a Field is stored
+type FieldType uint8
+
+const (
+	// SkipType Field which is ignored, e.g. Err with a nil error
+	SkipType FieldType = iota
+	// StringType Field holding a string
+	StringType
+	// IntType Field holding an int64
+	IntType
+	// FloatType Field holding a float64
+	FloatType
+	// BoolType Field holding a bool
+	BoolType
+	// DurationType Field holding a time.Duration
+	DurationType
+	// ErrorType Field holding an error, logged as its message
+	ErrorType
+	// AnyType Field holding any other value
+	AnyType
+)
+
+// Field is a typed key value pair for With. Strings and numbers are stored
+// without boxing them in an interface, so building fields doesn't allocate.
+type Field struct {
+	Key       string
+	Type      FieldType
+	Integer   int64
+	String    string
+	Interface interface{}
+}
+
+// String returns a Field holding a string
+func String(key, value string) Field {
+	return Field{Key: key, Type: StringType, String: value}
+}
+
+// Int returns a Field holding an int
+func Int(key string, value int) Field {
+	return Field{Key: key, Type: IntType, Integer: int64(value)}
+}
+
+// Int64 returns a Field holding an int64
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Type: IntType, Integer: value}
+}
+
+// Float64 returns a Field holding a float64
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Type: FloatType, Integer: int64(math.Float64bits(value))}
+}
+
+// Bool returns a Field holding a bool
+func Bool(key string, value bool) Field {
+	var i int64
+	if value {
+		i = 1
+	}
+	return Field{Key: key, Type: BoolType, Integer: i}
+}
+
+// Duration returns a Field holding a time.Duration
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Type: DurationType, Integer: int64(value)}
+}
+
+// Bytes returns a Field holding a ByteSize
+func Bytes(key string, value int64) Field {
+	return Field{Key: key, Type: AnyType, Interface: ByteSize(value)}
+}
+
+// Err returns a Field holding the message of the error under ErrorKey, a nil
+// error is skipped
+func Err(err error) Field {
+	if err == nil {
+		return Field{Type: SkipType}
+	}
+	return Field{Key: ErrorKey, Type: ErrorType, Interface: err}
+}
+
+// Any returns a Field holding any value
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Type: AnyType, Interface: value}
+}
+
+// Value returns the value of the field as it is added to the field map
+func (f Field) Value() interface{} {
+	switch f.Type {
+	case StringType:
+		return f.String
+	case IntType:
+		return f.Integer
+	case FloatType:
+		return math.Float64frombits(uint64(f.Integer))
+	case BoolType:
+		return f.Integer == 1
+	case DurationType:
+		return time.Duration(f.Integer)
+	case ErrorType:
+		return f.Interface.(error).Error()
+	default:
+		return f.Interface
+	}
+}
+
+// fieldMap returns the fields as map for WithFields
+func fieldMap(fields []Field) map[string]interface{} {
+	m := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if field.Type != SkipType {
+			m[field.Key] = field.Value()
+		}
+	}
+	return m
+}
+
+// isReserved reports whether the key is one of reservedKeys
+func isReserved(key string) bool {
+	for _, reserved := range reservedKeys {
+		if key == reserved {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package log
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestWithTypedFields(t *testing.T) {
+	l, logs := NewObserver()
+
+	l.With(
+		String("user", "alice"),
+		Int("status", 200),
+		Float64("ratio", 0.5),
+		Bool("cached", true),
+		Duration("took", time.Second),
+		Err(nil),
+		Err(errors.New("boom")),
+		String("msg", "reserved"),
+	).Info("typed")
+
+	fields := logs.FilterLevel(LevelInfo)[0].Fields
+	want := Fields{
+		"user":       "alice",
+		"status":     int64(200),
+		"ratio":      0.5,
+		"cached":     true,
+		"took":       time.Second,
+		ErrorKey:     "boom",
+		"fields.msg": "reserved",
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("field %s = %#v, want %#v", k, fields[k], v)
+		}
+	}
+	if len(fields) != len(want) {
+		t.Errorf("fields = %v, want %v", fields, want)
+	}
+}
+
+func BenchmarkWith(b *testing.B) {
+	l := NewWithOptions(WithOutput(ioutil.Discard), WithLevel(LevelInfo))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.With(String("method", "GET"), String("path", "/users"), Int("status", 200), Duration("took", time.Millisecond))
+	}
+}
+
+func BenchmarkWithFields(b *testing.B) {
+	l := NewWithOptions(WithOutput(ioutil.Discard), WithLevel(LevelInfo))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.WithFields(Fields{"method": "GET", "path": "/users", "status": 200, "took": time.Millisecond})
+	}
+}
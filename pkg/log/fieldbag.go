@@ -0,0 +1,86 @@
+package log
+
+import (
+	"context"
+	"sync"
+)
+
+// fieldBag holds the fields added to a context through AddField, it is shared
+// by all contexts derived from the one it was attached to
+type fieldBag struct {
+	mu     sync.RWMutex
+	fields Fields
+}
+
+// bagOf returns the field bag of ctx, nil if there is none
+func bagOf(ctx context.Context) *fieldBag {
+	if ctx == nil {
+		return nil
+	}
+	bag, _ := ctx.Value(bagKey).(*fieldBag)
+	return bag
+}
+
+// withFieldBag returns ctx with a field bag attached, ctx itself if it already
+// carries one
+func withFieldBag(ctx context.Context) context.Context {
+	if bagOf(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, bagKey, &fieldBag{})
+}
+
+// AddField adds the field to the logger stored in ctx by NewContext, so every
+// logger taken from ctx or a context derived from it through FromContext or
+// WithContext carries it afterwards, e.g. the user id resolved in the middle
+// of a request. The field is visible to earlier middlewares as well, like the
+// access log. It is safe to call from several goroutines of a request and
+// reports false if ctx carries no logger.
+func AddField(ctx context.Context, key string, value interface{}) bool {
+	bag := bagOf(ctx)
+	if bag == nil {
+		return false
+	}
+
+	bag.mu.Lock()
+	defer bag.mu.Unlock()
+
+	// the map is replaced, loggers may still hold a snapshot of it
+	fields := make(Fields, len(bag.fields)+1)
+	for k, v := range bag.fields {
+		fields[k] = v
+	}
+	fields[key] = value
+	bag.fields = fields
+	return true
+}
+
+// RemoveField removes a field added through AddField
+func RemoveField(ctx context.Context, key string) {
+	bag := bagOf(ctx)
+	if bag == nil {
+		return
+	}
+
+	bag.mu.Lock()
+	defer bag.mu.Unlock()
+
+	if _, ok := bag.fields[key]; !ok {
+		return
+	}
+	fields := make(Fields, len(bag.fields))
+	for k, v := range bag.fields {
+		if k != key {
+			fields[k] = v
+		}
+	}
+	bag.fields = fields
+}
+
+// snapshot returns the current fields, the map must not be modified
+func (b *fieldBag) snapshot() Fields {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.fields
+}
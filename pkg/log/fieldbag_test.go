@@ -0,0 +1,64 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestAddFieldMidRequest(t *testing.T) {
+	l, logs := NewObserver()
+	ctx := NewContext(context.Background(), l)
+
+	FromContext(ctx).Info("request received")
+	if !AddField(ctx, "user_id", 42) {
+		t.Fatalf("AddField failed on a context from NewContext")
+	}
+	FromContext(ctx).Info("user resolved")
+	FromContext(ctx).WithFields(Fields{"step": "load"}).Info("profile loaded")
+	RemoveField(ctx, "user_id")
+	FromContext(ctx).Info("response sent")
+
+	entries := logs.All()
+	if len(entries) != 4 {
+		t.Fatalf("logged %d entries, want 4", len(entries))
+	}
+	for i, want := range []interface{}{nil, 42, 42, nil} {
+		if got := entries[i].Fields["user_id"]; got != want {
+			t.Errorf("entry %q user_id = %v, want %v", entries[i].Message, got, want)
+		}
+	}
+}
+
+func TestAddFieldWithoutBag(t *testing.T) {
+	if AddField(context.Background(), "user_id", 42) {
+		t.Errorf("AddField succeeded on a context without logger")
+	}
+}
+
+func TestAddFieldConcurrent(t *testing.T) {
+	l, logs := NewObserver()
+	ctx := NewContext(context.Background(), l)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				AddField(ctx, fmt.Sprintf("worker_%d", i), j)
+				FromContext(ctx).Debug("working")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	FromContext(ctx).Info("done")
+	last := logs.FilterLevel(LevelInfo)[0]
+	for i := 0; i < 8; i++ {
+		if got := last.Fields[fmt.Sprintf("worker_%d", i)]; got != 49 {
+			t.Errorf("worker_%d = %v, want 49", i, got)
+		}
+	}
+}
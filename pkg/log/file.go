@@ -10,7 +10,7 @@ import (
 
 // LogrusFileHook hook for logrus to write log to file
 type LogrusFileHook struct {
-	file      *os.File
+	file      *rotatingFile
 	flag      int
 	chmod     os.FileMode
 	formatter logrus.Formatter
@@ -18,8 +18,14 @@ type LogrusFileHook struct {
 
 // NewLogrusFileHook returns new file hook object for logrus
 func NewLogrusFileHook(file string, flag int, chmod os.FileMode) (*LogrusFileHook, error) {
+	return NewLogrusRotatingFileHook(file, flag, chmod, FileRotation{})
+}
+
+// NewLogrusRotatingFileHook returns new file hook object for logrus which rotates
+// the file according to the given rotation settings
+func NewLogrusRotatingFileHook(file string, flag int, chmod os.FileMode, rotation FileRotation) (*LogrusFileHook, error) {
 	plainFormatter := getFormatter(true)
-	logFile, err := os.OpenFile(file, flag, chmod)
+	logFile, err := newRotatingFile(file, flag, chmod, rotation)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "unable to write file on filehook %v", err)
 		return nil, err
@@ -34,8 +40,7 @@ func (hook *LogrusFileHook) Fire(entry *logrus.Entry) error {
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	line := string(plainFormat)
-	_, err = hook.file.WriteString(line)
+	_, err = hook.file.Write(plainFormat)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "unable to write file on filehook (entry.String): %v", err)
 		return err
@@ -48,3 +53,8 @@ func (hook *LogrusFileHook) Fire(entry *logrus.Entry) error {
 func (hook *LogrusFileHook) Levels() []logrus.Level {
 	return logrus.AllLevels
 }
+
+// Close flushes and closes the log file
+func (hook *LogrusFileHook) Close() error {
+	return hook.file.Close()
+}
@@ -0,0 +1,32 @@
+package log
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCloseFlushesFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.log")
+	l := NewWithOptions(WithOutput(ioutil.Discard), WithLevel(LevelInfo), WithFile(file))
+
+	l.Info("written before close")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatalf("failed to reopen log file: %v", err)
+	}
+	defer f.Close()
+	content, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "written before close") {
+		t.Errorf("log file doesn't contain the line, got %q", content)
+	}
+}
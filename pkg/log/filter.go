@@ -0,0 +1,24 @@
+package log
+
+// filter handler which logs only the entries a predicate keeps
+type filter struct {
+	keep func(level Level, msg string, fields Fields) bool
+}
+
+// NewFiltered returns a logger which logs only the entries for which keep
+// returns true, e.g. to mute the access logs of health checks. The predicate
+// receives the fields added through WithFields and the like. Fatal and panic
+// entries are never dropped.
+//
+//	logger = log.NewFiltered(logger, func(_ log.Level, _ string, fields log.Fields) bool {
+//		return fields["path"] != "/healthz"
+//	})
+func NewFiltered(l Logger, keep func(level Level, msg string, fields Fields) bool) Logger {
+	return wrap(l, &filter{keep: keep})
+}
+
+func (f *filter) handle(l Logger, level Level, msg string) {
+	if f.keep(level, msg, fieldsOf(l)) {
+		logAt(l, level, msg)
+	}
+}
@@ -0,0 +1,38 @@
+package log
+
+import "testing"
+
+func TestFilteredDropsHealthChecks(t *testing.T) {
+	observer, logs := NewObserver()
+	l := NewFiltered(observer, func(_ Level, _ string, fields Fields) bool {
+		return fields["path"] != "/healthz"
+	})
+
+	l.WithFields(Fields{"path": "/healthz"}).Info("GET /healthz")
+	l.WithFields(Fields{"path": "/api/users"}).Info("GET /api/users")
+	l.WithPrefix("http").WithFields(Fields{"path": "/healthz"}).Warn("GET /healthz")
+	l.Info("no path")
+
+	var msgs []string
+	for _, e := range logs.All() {
+		msgs = append(msgs, e.Message)
+	}
+	if len(msgs) != 2 || msgs[0] != "GET /api/users" || msgs[1] != "no path" {
+		t.Errorf("logged %q, want the entries without path=/healthz", msgs)
+	}
+}
+
+func TestFilteredKeepsFatal(t *testing.T) {
+	observer, logs := NewObserver()
+	l := NewFiltered(observer, func(Level, string, Fields) bool { return false })
+
+	l.Error("dropped")
+	func() {
+		defer func() { _ = recover() }()
+		l.Panic("kept")
+	}()
+
+	if entries := logs.All(); len(entries) != 1 || entries[0].Message != "kept" {
+		t.Errorf("got entries %+v, want only the panic entry", entries)
+	}
+}
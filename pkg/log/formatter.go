@@ -11,6 +11,7 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/mgutz/ansi"
 	"github.com/sirupsen/logrus"
@@ -28,6 +29,7 @@ var (
 		FatalLevelStyle: "red",
 		PanicLevelStyle: "red",
 		DebugLevelStyle: "blue",
+		TraceLevelStyle: "blue",
 		PrefixStyle:     "cyan",
 		TimestampStyle:  "black+h",
 	}
@@ -38,6 +40,7 @@ var (
 		FatalLevelColor: ansi.ColorFunc(""),
 		PanicLevelColor: ansi.ColorFunc(""),
 		DebugLevelColor: ansi.ColorFunc(""),
+		TraceLevelColor: ansi.ColorFunc(""),
 		PrefixColor:     ansi.ColorFunc(""),
 		TimestampColor:  ansi.ColorFunc(""),
 	}
@@ -45,6 +48,8 @@ var (
 
 	// ErrUnknownLevel unknown log level
 	ErrUnknownLevel = errors.New("unknown log level")
+	// ErrUnknownFormat unknown log format
+	ErrUnknownFormat = errors.New("unknown log format")
 )
 
 func miniTS() int {
@@ -58,8 +63,10 @@ type colorScheme struct {
 	FatalLevelStyle string
 	PanicLevelStyle string
 	DebugLevelStyle string
+	TraceLevelStyle string
 	PrefixStyle     string
 	TimestampStyle  string
+	FieldStyle      string
 }
 
 type compiledColorScheme struct {
@@ -69,8 +76,11 @@ type compiledColorScheme struct {
 	FatalLevelColor func(string) string
 	PanicLevelColor func(string) string
 	DebugLevelColor func(string) string
+	TraceLevelColor func(string) string
 	PrefixColor     func(string) string
 	TimestampColor  func(string) string
+	// FieldColor colors field keys, they take the level color if it is nil.
+	FieldColor func(string) string
 }
 
 type textFormatter struct {
@@ -114,6 +124,21 @@ type textFormatter struct {
 	// Its default value is zero, which means no padding will be applied for msg.
 	SpacePadding int
 
+	// Expand nested maps and structs into dotted keys up to this depth, zero
+	// disables expanding.
+	FlattenDepth int
+
+	// Rendering of messages and field values containing newlines.
+	Multiline Multiline
+
+	// Render durations and byte sizes readable, like 1.2s and 3.4MB.
+	HumanizeValues bool
+
+	// Align the fields of formatted output in columns of this width, sorted by
+	// key. Fields longer than a column span as many columns as they need. Zero
+	// disables aligning.
+	FieldColumnWidth int
+
 	// Color scheme to use.
 	colorScheme *compiledColorScheme
 
@@ -130,10 +155,33 @@ func getCompiledColor(main string, fallback string) func(string) string {
 	} else {
 		style = fallback
 	}
+	if strings.HasPrefix(style, "#") {
+		return trueColorFunc(style)
+	}
 	return ansi.ColorFunc(style)
 }
 
+// trueColorFunc returns a func coloring text in the 24-bit color given as
+// "#rrggbb", invalid colors leave the text as is
+func trueColorFunc(hex string) func(string) string {
+	var r, g, b uint8
+	if _, err := fmt.Sscanf(hex, "#%02x%02x%02x", &r, &g, &b); err != nil || len(hex) != 7 {
+		return ansi.ColorFunc("")
+	}
+	start := fmt.Sprintf("\x1b[38;2;%d;%d;%dm", r, g, b)
+	return func(text string) string {
+		if text == "" {
+			return text
+		}
+		return start + text + ansi.Reset
+	}
+}
+
 func compileColorScheme(s *colorScheme) *compiledColorScheme {
+	var fieldColor func(string) string
+	if s.FieldStyle != "" {
+		fieldColor = getCompiledColor(s.FieldStyle, "")
+	}
 	return &compiledColorScheme{
 		InfoLevelColor:  getCompiledColor(s.InfoLevelStyle, defaultColorScheme.InfoLevelStyle),
 		WarnLevelColor:  getCompiledColor(s.WarnLevelStyle, defaultColorScheme.WarnLevelStyle),
@@ -141,8 +189,10 @@ func compileColorScheme(s *colorScheme) *compiledColorScheme {
 		FatalLevelColor: getCompiledColor(s.FatalLevelStyle, defaultColorScheme.FatalLevelStyle),
 		PanicLevelColor: getCompiledColor(s.PanicLevelStyle, defaultColorScheme.PanicLevelStyle),
 		DebugLevelColor: getCompiledColor(s.DebugLevelStyle, defaultColorScheme.DebugLevelStyle),
+		TraceLevelColor: getCompiledColor(s.TraceLevelStyle, defaultColorScheme.TraceLevelStyle),
 		PrefixColor:     getCompiledColor(s.PrefixStyle, defaultColorScheme.PrefixStyle),
 		TimestampColor:  getCompiledColor(s.TimestampStyle, defaultColorScheme.TimestampStyle),
+		FieldColor:      fieldColor,
 	}
 }
 
@@ -152,10 +202,20 @@ func (f *textFormatter) init(entry *logrus.Entry) {
 	}
 	if entry.Logger != nil {
 		f.isTerminal = f.checkIfTerminal(entry.Logger.Out)
+		// Windows consoles print escape codes as is unless virtual terminal
+		// processing is enabled, colors are disabled if that fails
+		if f.isTerminal && !enableVirtualTerminal(entry.Logger.Out) {
+			f.DisableColors = true
+		}
 	}
 }
 
 func (f *textFormatter) checkIfTerminal(w io.Writer) bool {
+	return isTerminal(w)
+}
+
+// isTerminal reports whether the writer is a terminal
+func isTerminal(w io.Writer) bool {
 	switch v := w.(type) {
 	case *os.File:
 		return terminal.IsTerminal(int(v.Fd()))
@@ -170,6 +230,12 @@ func (f *textFormatter) setColorScheme(colorScheme *colorScheme) {
 
 // Format func used by logrus to format the log
 func (f *textFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	entry = flattenGroups(entry, f.FlattenDepth)
+	entry = renameClashes(entry)
+	entry = renderMultiline(entry, f.Multiline)
+	if f.HumanizeValues {
+		entry = humanizeValues(entry)
+	}
 	var b *bytes.Buffer
 	var keys = make([]string, 0, len(entry.Data))
 	for k := range entry.Data {
@@ -186,8 +252,6 @@ func (f *textFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 		b = &bytes.Buffer{}
 	}
 
-	prefixFieldClashes(entry.Data)
-
 	f.Do(func() { f.init(entry) })
 
 	isFormatted := f.ForceFormatting || f.isTerminal
@@ -218,7 +282,14 @@ func (f *textFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 			f.appendKeyValue(b, "msg", entry.Message, lastKeyIdx >= 0)
 		}
 		for i, key := range keys {
-			f.appendKeyValue(b, key, entry.Data[key], lastKeyIdx != i)
+			f.appendKeyValue(b, key, entry.Data[key], lastKeyIdx != i || entry.HasCaller())
+		}
+		if entry.HasCaller() {
+			function, file := callerPrettyfier(entry.Caller)
+			if function != "" {
+				f.appendKeyValue(b, "func", function, true)
+			}
+			f.appendKeyValue(b, "file", file, false)
 		}
 	}
 
@@ -240,9 +311,15 @@ func (f *textFormatter) printColored(b *bytes.Buffer, entry *logrus.Entry, keys
 		levelColor = colorScheme.FatalLevelColor
 	case logrus.PanicLevel:
 		levelColor = colorScheme.PanicLevelColor
+	case logrus.TraceLevel:
+		levelColor = colorScheme.TraceLevelColor
 	default:
 		levelColor = colorScheme.DebugLevelColor
 	}
+	keyColor := levelColor
+	if colorScheme.FieldColor != nil {
+		keyColor = colorScheme.FieldColor
+	}
 
 	if entry.Level != logrus.WarnLevel {
 		levelText = entry.Level.String()
@@ -284,10 +361,30 @@ func (f *textFormatter) printColored(b *bytes.Buffer, entry *logrus.Entry, keys
 		}
 		_, _ = fmt.Fprintf(b, "%s %s%s "+messageFormat, colorScheme.TimestampColor(timestamp), level, prefix, message)
 	}
+	var fields [][2]string
 	for _, k := range keys {
 		if k != "prefix" {
-			v := entry.Data[k]
-			_, _ = fmt.Fprintf(b, " %s=%+v", levelColor(k), v)
+			fields = append(fields, [2]string{k, fmt.Sprintf("%+v", entry.Data[k])})
+		}
+	}
+	if f.FieldColumnWidth > 0 && f.DisableSorting {
+		sort.Slice(fields, func(i, j int) bool { return fields[i][0] < fields[j][0] })
+	}
+	if entry.HasCaller() {
+		function, file := callerPrettyfier(entry.Caller)
+		if function != "" {
+			fields = append(fields, [2]string{"func", function})
+		}
+		fields = append(fields, [2]string{"file", file})
+	}
+	for i, field := range fields {
+		_, _ = fmt.Fprintf(b, " %s=%s", keyColor(field[0]), field[1])
+		if f.FieldColumnWidth > 0 && i < len(fields)-1 {
+			// pad by the visible length, the key may carry color codes
+			n := 1 + utf8.RuneCountInString(field[0]) + 1 + utf8.RuneCountInString(field[1])
+			if rest := n % f.FieldColumnWidth; rest != 0 {
+				b.WriteString(strings.Repeat(" ", f.FieldColumnWidth-rest))
+			}
 		}
 	}
 }
@@ -346,26 +443,3 @@ func (f *textFormatter) appendValue(b *bytes.Buffer, value interface{}) {
 		_, _ = fmt.Fprint(b, value)
 	}
 }
-
-// This is to not silently overwrite `time`, `msg` and `level` fields when
-// dumping it. If this code wasn't there doing:
-//
-//  logrus.WithField("level", 1).Info("hello")
-//
-// would just silently drop the user provided level. Instead with this code
-// it'll be logged as:
-//
-//  {"level": "info", "fields.level": 1, "msg": "hello", "time": "..."}
-func prefixFieldClashes(data logrus.Fields) {
-	if t, ok := data["time"]; ok {
-		data["fields.time"] = t
-	}
-
-	if m, ok := data["msg"]; ok {
-		data["fields.msg"] = m
-	}
-
-	if l, ok := data["level"]; ok {
-		data["fields.level"] = l
-	}
-}
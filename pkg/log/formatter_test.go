@@ -0,0 +1,109 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// fixedClock Clock which always returns the same time
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestFieldColumnsGolden(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(
+		WithOutput(&buf),
+		WithColors(false),
+		WithClock(fixedClock(time.Date(2024, 3, 1, 12, 30, 45, 123456000, time.UTC))),
+		WithFieldColumns(16),
+	)
+
+	l.WithFields(Fields{"method": "GET", "status": 200, "path": "/api/users/42"}).Info("request handled")
+	l.WithPrefix("db").WithFields(Fields{"rows": 3, "table": "users"}).Warn("slow query")
+
+	golden := "" +
+		"[2024-03-01 12:30:45.123456]  INFO request handled                               method=GET      path=/api/users/42              status=200\n" +
+		"[2024-03-01 12:30:45.123456]  WARN db: slow query                                    rows=3          table=users\n"
+	if got := buf.String(); got != golden {
+		t.Errorf("got:\n%s\nwant:\n%s", got, golden)
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithFormat(&buf, LevelInfo, "", FormatJSON)
+
+	l.WithPrefix("api").WithFields(Fields{"user": "alice", "attempt": 2}).Infof("login %s", "succeeded")
+
+	out := buf.Bytes()
+	if bytes.Contains(out, []byte("\x1b[")) {
+		t.Errorf("JSON output contains color codes: %q", out)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(out, &entry); err != nil {
+		t.Fatalf("output isn't a single JSON object: %q: %v", out, err)
+	}
+	want := map[string]interface{}{
+		"level":   "info",
+		"msg":     "login succeeded",
+		"prefix":  "api",
+		"user":    "alice",
+		"attempt": float64(2),
+	}
+	for k, v := range want {
+		if entry[k] != v {
+			t.Errorf("%s = %#v, want %#v", k, entry[k], v)
+		}
+	}
+	ts, _ := entry["time"].(string)
+	if _, err := time.Parse(timestampFormat, ts); err != nil {
+		t.Errorf("time %q doesn't use the text timestamp format: %v", ts, err)
+	}
+}
+
+func TestUTCTimestamps(t *testing.T) {
+	var buf bytes.Buffer
+	zone := time.FixedZone("CEST", 2*60*60)
+	l := NewWithOptions(
+		WithOutput(&buf),
+		WithFormat(FormatJSON),
+		WithClock(fixedClock(time.Date(2024, 3, 1, 14, 30, 0, 0, zone))),
+		WithTimestampFormat(time.RFC3339),
+		WithUTC(),
+	)
+
+	l.Info("converted")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("output isn't a JSON object: %q: %v", buf.Bytes(), err)
+	}
+	if want := "2024-03-01T12:30:00Z"; entry["time"] != want {
+		t.Errorf("time = %v, want %s", entry["time"], want)
+	}
+}
+
+func TestTimestampFormatRFC3339Nano(t *testing.T) {
+	clock := fixedClock(time.Date(2024, 3, 1, 12, 30, 45, 123456789, time.UTC))
+	for _, format := range []Format{FormatText, FormatJSON, FormatLogfmt} {
+		var buf bytes.Buffer
+		l := NewWithOptions(
+			WithOutput(&buf),
+			WithColors(false),
+			WithFormat(format),
+			WithClock(clock),
+			WithTimestampFormat(time.RFC3339Nano),
+		)
+
+		l.Info("nano")
+
+		if want := "2024-03-01T12:30:45.123456789Z"; !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("%s: %q doesn't contain %s", format, buf.Bytes(), want)
+		}
+	}
+}
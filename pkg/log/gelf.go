@@ -0,0 +1,95 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// gelfVersion version of the GELF payload specification
+const gelfVersion = "1.1"
+
+// gelfFormatter renders entries as Graylog Extended Log Format JSON
+type gelfFormatter struct {
+	// Host is logged as host, it defaults to the hostname of the machine.
+	Host string
+	// Expand nested maps and structs into dotted keys up to this depth, GELF
+	// doesn't support nested values.
+	FlattenDepth int
+}
+
+// newGELFFormatter returns a GELF formatter for the given host, falling back to
+// the hostname of the machine, and flatten depth, falling back to
+// DefaultFlattenDepth
+func newGELFFormatter(host string, flattenDepth int) *gelfFormatter {
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	if flattenDepth <= 0 {
+		flattenDepth = DefaultFlattenDepth
+	}
+	return &gelfFormatter{Host: host, FlattenDepth: flattenDepth}
+}
+
+// Format func used by logrus to format the log. Custom fields are prefixed with
+// an underscore as required by GELF.
+func (f *gelfFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	entry = flattenGroups(entry, f.FlattenDepth)
+	doc := make(map[string]interface{}, len(entry.Data)+6)
+
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		if k == "id" {
+			k = "id_"
+		}
+		doc["_"+k] = v
+	}
+
+	shortMessage := entry.Message
+	if i := strings.IndexByte(shortMessage, '\n'); i >= 0 {
+		shortMessage = shortMessage[:i]
+		doc["full_message"] = entry.Message
+	}
+
+	doc["version"] = gelfVersion
+	doc["host"] = f.Host
+	doc["short_message"] = shortMessage
+	doc["timestamp"] = float64(entry.Time.UnixNano()) / 1e9
+	doc["level"] = syslogSeverity(entry.Level)
+	if entry.HasCaller() {
+		doc["_file"] = entry.Caller.File
+		doc["_line"] = entry.Caller.Line
+		if entry.Caller.Function != "" {
+			doc["_func"] = entry.Caller.Function
+		}
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal gelf message: %v", err)
+	}
+	return append(b, '\n'), nil
+}
+
+// syslogSeverity maps a logrus level to the numeric syslog severity
+func syslogSeverity(level logrus.Level) int {
+	switch level {
+	case logrus.PanicLevel:
+		return 0
+	case logrus.FatalLevel:
+		return 2
+	case logrus.ErrorLevel:
+		return 3
+	case logrus.WarnLevel:
+		return 4
+	case logrus.InfoLevel:
+		return 6
+	default:
+		return 7
+	}
+}
@@ -0,0 +1,48 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestGELFFormat(t *testing.T) {
+	f := newGELFFormatter("api-1", 0)
+	entry := &logrus.Entry{
+		Time:    time.Date(2024, 3, 1, 12, 0, 0, 500000000, time.UTC),
+		Level:   logrus.ErrorLevel,
+		Message: "query failed\nselect * from users",
+		Data:    logrus.Fields{"id": 7, "err": errors.New("timeout"), "user": "alice"},
+	}
+
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("output isn't a JSON object: %q: %v", b, err)
+	}
+	want := map[string]interface{}{
+		"version":       "1.1",
+		"host":          "api-1",
+		"short_message": "query failed",
+		"full_message":  "query failed\nselect * from users",
+		"timestamp":     1709294400.5,
+		"level":         float64(3),
+		"_id_":          float64(7),
+		"_err":          "timeout",
+		"_user":         "alice",
+	}
+	for k, v := range want {
+		if doc[k] != v {
+			t.Errorf("%s = %#v, want %#v", k, doc[k], v)
+		}
+	}
+	if len(doc) != len(want) {
+		t.Errorf("got %d keys, want %d: %v", len(doc), len(want), doc)
+	}
+}
@@ -0,0 +1,161 @@
+package log
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// groupSeparator separates the group names in the keys of flattened groups
+	groupSeparator = "."
+	// DefaultFlattenDepth is the number of levels WithFlattening expands if no
+	// depth is given
+	DefaultFlattenDepth = 3
+)
+
+// mergeGroup returns a copy of group with the fields added in the group nested
+// below it along path. The group itself is never modified as it is shared with
+// the logger it was added to.
+func mergeGroup(group Fields, path []string, fields map[string]interface{}) Fields {
+	merged := make(Fields, len(group)+len(fields))
+	for k, v := range group {
+		merged[k] = v
+	}
+	if len(path) == 0 {
+		for k, v := range fields {
+			merged[k] = v
+		}
+		return merged
+	}
+
+	sub, _ := group[path[0]].(Fields)
+	merged[path[0]] = mergeGroup(sub, path[1:], fields)
+	return merged
+}
+
+// flattenGroups returns the entry with the fields of groups added by WithGroup
+// expanded into dotted keys, for formats which can't nest. Nested maps and
+// structs are expanded as well up to the given depth. The entry is returned as
+// is if there is nothing to expand.
+func flattenGroups(entry *logrus.Entry, depth int) *logrus.Entry {
+	nested := false
+	for _, v := range entry.Data {
+		if _, ok := v.(Fields); ok || (depth > 0 && flattenable(v)) {
+			nested = true
+			break
+		}
+	}
+	if !nested {
+		return entry
+	}
+
+	data := make(logrus.Fields, len(entry.Data))
+	addFlattened(data, "", entry.Data, depth)
+	flat := *entry
+	flat.Data = data
+	return &flat
+}
+
+// renameClashes returns the entry with fields named like its time, level or
+// msg renamed with the "fields." prefix, as formatters writing the metadata
+// next to the fields would lose or duplicate them otherwise. The loggers
+// rename such keys already, but hooks may still add them. The field map is
+// shared, so a renamed entry is a copy with a new map.
+func renameClashes(entry *logrus.Entry) *logrus.Entry {
+	var data logrus.Fields
+	for _, key := range reservedKeys {
+		// the prefix is set by WithPrefix and rendered by the formatters
+		if key == "prefix" {
+			continue
+		}
+		v, ok := entry.Data[key]
+		if !ok {
+			continue
+		}
+		if data == nil {
+			data = make(logrus.Fields, len(entry.Data))
+			for k, value := range entry.Data {
+				data[k] = value
+			}
+		}
+		delete(data, key)
+		data[reservedKeyPrefix+key] = v
+	}
+	if data == nil {
+		return entry
+	}
+	renamed := *entry
+	renamed.Data = data
+	return &renamed
+}
+
+// addFlattened adds the fields to data with their keys prefixed by the group
+func addFlattened(data logrus.Fields, group string, fields map[string]interface{}, depth int) {
+	for k, v := range fields {
+		if group != "" {
+			k = group + groupSeparator + k
+		}
+		if sub, ok := v.(Fields); ok {
+			addFlattened(data, k, sub, depth)
+			continue
+		}
+		if depth > 0 && flattenable(v) {
+			addFlattened(data, k, nestedFields(v), depth-1)
+			continue
+		}
+		data[k] = v
+	}
+}
+
+// flattenable reports whether the value is a map with string keys or a struct
+// which doesn't render itself
+func flattenable(v interface{}) bool {
+	switch v.(type) {
+	case nil, error, fmt.Stringer, encoding.TextMarshaler:
+		return false
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	switch rv.Kind() {
+	case reflect.Map:
+		return rv.Type().Key().Kind() == reflect.String
+	case reflect.Struct:
+		return true
+	}
+	return false
+}
+
+// nestedFields returns the entries of a map or the exported fields of a struct,
+// struct fields are named by their json tag if present
+func nestedFields(v interface{}) map[string]interface{} {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	fields := make(map[string]interface{})
+
+	if rv.Kind() == reflect.Map {
+		iter := rv.MapRange()
+		for iter.Next() {
+			fields[iter.Key().String()] = iter.Value().Interface()
+		}
+		return fields
+	}
+
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		fields[name] = rv.Field(i).Interface()
+	}
+	return fields
+}
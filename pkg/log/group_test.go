@@ -0,0 +1,71 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// address struct logged as a nested field value
+type address struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+func TestFlatteningTextAndJSON(t *testing.T) {
+	render := func(format Format) string {
+		var buf bytes.Buffer
+		l := NewWithOptions(WithOutput(&buf), WithFormat(format), WithFlattening(0))
+		l.WithGroup("req").WithFields(Fields{
+			"user": map[string]interface{}{"id": 7, "address": address{City: "Bern", Zip: "3000"}},
+		}).Info("handled")
+		return buf.String()
+	}
+
+	text := render(FormatText)
+	for _, want := range []string{"req.user.id=7", "req.user.address.city=Bern", "req.user.address.zip=3000"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text output lacks %q: %s", want, text)
+		}
+	}
+
+	var entry struct {
+		Req struct {
+			User struct {
+				ID      int     `json:"id"`
+				Address address `json:"address"`
+			} `json:"user"`
+		} `json:"req"`
+	}
+	out := render(FormatJSON)
+	if err := json.Unmarshal([]byte(out), &entry); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", out, err)
+	}
+	if entry.Req.User.ID != 7 || entry.Req.User.Address.City != "Bern" {
+		t.Errorf("JSON output isn't nested: %s", out)
+	}
+	if strings.Contains(out, "req.user") {
+		t.Errorf("JSON output was flattened: %s", out)
+	}
+}
+
+func TestFlatteningRedactsNestedKeys(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(WithOutput(&buf), WithFormat(FormatText), WithFlattening(0), WithRedaction())
+
+	l.WithFields(Fields{
+		"login": map[string]interface{}{"user": "alice", "password": "hunter2"},
+		"c":     credentials{User: "bob", Password: "s3cret"},
+	}).Info("login")
+
+	out := buf.String()
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "s3cret") {
+		t.Errorf("flattened password reached the writer in cleartext: %s", out)
+	}
+	for _, want := range []string{"login.password=" + RedactedValue, "c.Password=" + RedactedValue, "login.user=alice"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("text output lacks %q: %s", want, out)
+		}
+	}
+}
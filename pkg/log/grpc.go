@@ -0,0 +1,88 @@
+package log
+
+import "fmt"
+
+// GRPCLogger adapts a Logger to the grpclog.LoggerV2 interface, it can be
+// installed with grpclog.SetLoggerV2(log.NewGRPCLogger(logger)). The adapter
+// only relies on the method set so the package does not depend on grpc.
+type GRPCLogger struct {
+	logger Logger
+}
+
+// NewGRPCLogger returns a grpclog.LoggerV2 compatible adapter for the logger
+func NewGRPCLogger(l Logger) *GRPCLogger {
+	return &GRPCLogger{logger: l.WithPrefix("grpc")}
+}
+
+// Info logs to info level
+func (g *GRPCLogger) Info(args ...interface{}) {
+	logAt(g.logger, LevelInfo, fmt.Sprint(args...))
+}
+
+// Infoln logs to info level
+func (g *GRPCLogger) Infoln(args ...interface{}) {
+	g.logger.Infoln(args...)
+}
+
+// Infof logs to info level
+func (g *GRPCLogger) Infof(format string, args ...interface{}) {
+	g.logger.Infof(format, args...)
+}
+
+// Warning logs to warn level
+func (g *GRPCLogger) Warning(args ...interface{}) {
+	logAt(g.logger, LevelWarn, fmt.Sprint(args...))
+}
+
+// Warningln logs to warn level
+func (g *GRPCLogger) Warningln(args ...interface{}) {
+	g.logger.Warnln(args...)
+}
+
+// Warningf logs to warn level
+func (g *GRPCLogger) Warningf(format string, args ...interface{}) {
+	g.logger.Warnf(format, args...)
+}
+
+// Error logs to error level
+func (g *GRPCLogger) Error(args ...interface{}) {
+	logAt(g.logger, LevelError, fmt.Sprint(args...))
+}
+
+// Errorln logs to error level
+func (g *GRPCLogger) Errorln(args ...interface{}) {
+	g.logger.Errorln(args...)
+}
+
+// Errorf logs to error level
+func (g *GRPCLogger) Errorf(format string, args ...interface{}) {
+	g.logger.Errorf(format, args...)
+}
+
+// Fatal logs to fatal level and exits
+func (g *GRPCLogger) Fatal(args ...interface{}) {
+	logAt(g.logger, LevelFatal, fmt.Sprint(args...))
+}
+
+// Fatalln logs to fatal level and exits
+func (g *GRPCLogger) Fatalln(args ...interface{}) {
+	g.logger.Fatalln(args...)
+}
+
+// Fatalf logs to fatal level and exits
+func (g *GRPCLogger) Fatalf(format string, args ...interface{}) {
+	g.logger.Fatalf(format, args...)
+}
+
+// V reports whether the grpc verbosity level is enabled, level 0 maps to
+// info, 1 to debug and everything above to trace
+func (g *GRPCLogger) V(level int) bool {
+	switch {
+	case level <= 0:
+		return g.logger.Enabled(LevelInfo)
+	case level == 1:
+		return g.logger.Enabled(LevelDebug)
+	default:
+		return g.logger.Enabled(LevelTrace)
+	}
+}
@@ -0,0 +1,67 @@
+package log
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// levelBody is the JSON body of the level handler
+type levelBody struct {
+	Level Level `json:"level"`
+}
+
+// LevelHandler returns a http.Handler to read and change the level of the
+// logger at runtime. GET responds with the current level as JSON, PUT and POST
+// set the level given by the level query parameter or the body, either as JSON
+// {"level":"debug"} or as plain text. Invalid levels are rejected with 400.
+func LevelHandler(l Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			level, err := levelFromRequest(r)
+			if err != nil {
+				writeLevelError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			l.SetLevel(level)
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			writeLevelError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(levelBody{Level: l.Level()})
+	})
+}
+
+// levelFromRequest reads the level from the query or the body of the request
+func levelFromRequest(r *http.Request) (Level, error) {
+	if level := r.URL.Query().Get("level"); level != "" {
+		return ParseLevel(level)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r.Body, 1024))
+	if err != nil {
+		return "", err
+	}
+	text := strings.TrimSpace(string(b))
+	if strings.HasPrefix(text, "{") {
+		var body levelBody
+		if err := json.Unmarshal(b, &body); err != nil {
+			return "", err
+		}
+		text = string(body.Level)
+	}
+	return ParseLevel(text)
+}
+
+// writeLevelError responds with the status and the message as JSON
+func writeLevelError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,87 @@
+package log
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func serveLevel(t *testing.T, h http.Handler, req *http.Request) (int, map[string]string) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON response %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, body
+}
+
+func TestLevelHandlerGet(t *testing.T) {
+	l := NewWithOptions(WithOutput(ioutil.Discard), WithLevel(LevelWarn))
+	h := LevelHandler(l)
+
+	status, body := serveLevel(t, h, httptest.NewRequest(http.MethodGet, "/log/level", nil))
+	if status != http.StatusOK {
+		t.Errorf("status = %d, want 200", status)
+	}
+	if body["level"] != "warn" {
+		t.Errorf("level = %q, want warn", body["level"])
+	}
+}
+
+func TestLevelHandlerSet(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *http.Request
+		want Level
+	}{
+		{"query", httptest.NewRequest(http.MethodPut, "/log/level?level=debug", nil), LevelDebug},
+		{"json body", httptest.NewRequest(http.MethodPost, "/log/level", strings.NewReader(`{"level":"error"}`)), LevelError},
+		{"text body", httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader("trace\n")), LevelTrace},
+		{"alias", httptest.NewRequest(http.MethodPut, "/log/level?level=warning", nil), LevelWarn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewWithOptions(WithOutput(ioutil.Discard), WithLevel(LevelInfo))
+			h := LevelHandler(l)
+
+			status, body := serveLevel(t, h, tt.req)
+			if status != http.StatusOK {
+				t.Errorf("status = %d, want 200", status)
+			}
+			if body["level"] != string(tt.want) {
+				t.Errorf("response level = %q, want %q", body["level"], tt.want)
+			}
+			if got := l.Level(); got != tt.want {
+				t.Errorf("logger level = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelHandlerInvalid(t *testing.T) {
+	l := NewWithOptions(WithOutput(ioutil.Discard), WithLevel(LevelInfo))
+	h := LevelHandler(l)
+
+	status, body := serveLevel(t, h, httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader("loud")))
+	if status != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", status)
+	}
+	if body["error"] == "" {
+		t.Errorf("response has no error message: %v", body)
+	}
+	if got := l.Level(); got != LevelInfo {
+		t.Errorf("logger level = %q, want it unchanged at info", got)
+	}
+
+	status, _ = serveLevel(t, h, httptest.NewRequest(http.MethodDelete, "/log/level", nil))
+	if status != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE status = %d, want 405", status)
+	}
+}
@@ -0,0 +1,48 @@
+package log
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fakeHook records the messages of the entries it fires for
+type fakeHook struct {
+	messages []string
+	closed   int
+}
+
+func (h *fakeHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel}
+}
+
+func (h *fakeHook) Fire(entry *logrus.Entry) error {
+	h.messages = append(h.messages, entry.Message)
+	return nil
+}
+
+func (h *fakeHook) Close() error {
+	h.closed++
+	return nil
+}
+
+func TestAddHook(t *testing.T) {
+	l := NewWithFormat(ioutil.Discard, LevelDebug, "", FormatJSON)
+	hook := &fakeHook{}
+	l.WithPrefix("db").AddHook(hook)
+
+	l.Info("started")
+	l.Error("first")
+	l.WithFields(Fields{"user": "alice"}).Errorf("second %d", 2)
+
+	if len(hook.messages) != 2 || hook.messages[0] != "first" || hook.messages[1] != "second 2" {
+		t.Errorf("hook fired for %q, want [first second 2]", hook.messages)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if hook.closed != 1 {
+		t.Errorf("hook closed %d times, want 1", hook.closed)
+	}
+}
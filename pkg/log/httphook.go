@@ -0,0 +1,222 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultHTTPBatchSize number of entries sent in one request
+	DefaultHTTPBatchSize = 100
+	// DefaultHTTPFlushInterval time after which a partial batch is sent
+	DefaultHTTPFlushInterval = 5 * time.Second
+	// DefaultHTTPQueueSize number of entries queued before new ones are dropped
+	DefaultHTTPQueueSize = 10000
+	// DefaultHTTPMaxRetries number of times a failed batch is retried
+	DefaultHTTPMaxRetries = 3
+	// DefaultHTTPRetryBackoff wait before the first retry, it doubles with every retry
+	DefaultHTTPRetryBackoff = 500 * time.Millisecond
+	// DefaultHTTPTimeout time limit of a request sent by the default client
+	DefaultHTTPTimeout = 10 * time.Second
+)
+
+// HTTPBatch configures how the HTTP hook batches and sends entries, zero values
+// fall back to the defaults
+type HTTPBatch struct {
+	// Client used to send the requests, a client with DefaultHTTPTimeout if
+	// nil. A client without timeout blocks Close while a collector hangs.
+	Client *http.Client
+	// Size is the maximum number of entries sent in one request.
+	Size int
+	// FlushInterval after which a partial batch is sent.
+	FlushInterval time.Duration
+	// QueueSize is the number of entries queued before new ones are dropped.
+	QueueSize int
+	// MaxRetries of a batch which failed with a transient error, a negative
+	// value disables retries.
+	MaxRetries int
+	// RetryBackoff before the first retry, it doubles with every retry.
+	RetryBackoff time.Duration
+}
+
+// LogrusHTTPHook hook for logrus to push log in batches to a HTTP endpoint. The
+// entries are sent as JSON array from a background goroutine, so the hook never
+// blocks the caller.
+type LogrusHTTPHook struct {
+	endpoint  string
+	batch     HTTPBatch
+	formatter logrus.Formatter
+
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan []byte
+	done    chan struct{}
+	dropped uint64
+}
+
+// NewHTTPHook returns new HTTP hook object for logrus which posts the entries
+// to the endpoint. Close must be called to send the remaining entries.
+func NewHTTPHook(endpoint string, batch HTTPBatch) *LogrusHTTPHook {
+	if batch.Client == nil {
+		batch.Client = &http.Client{Timeout: DefaultHTTPTimeout}
+	}
+	if batch.Size <= 0 {
+		batch.Size = DefaultHTTPBatchSize
+	}
+	if batch.FlushInterval <= 0 {
+		batch.FlushInterval = DefaultHTTPFlushInterval
+	}
+	if batch.QueueSize <= 0 {
+		batch.QueueSize = DefaultHTTPQueueSize
+	}
+	if batch.MaxRetries < 0 {
+		batch.MaxRetries = 0
+	} else if batch.MaxRetries == 0 {
+		batch.MaxRetries = DefaultHTTPMaxRetries
+	}
+	if batch.RetryBackoff <= 0 {
+		batch.RetryBackoff = DefaultHTTPRetryBackoff
+	}
+
+	hook := &LogrusHTTPHook{
+		endpoint:  endpoint,
+		batch:     batch,
+		formatter: getJSONFormatter(),
+		queue:     make(chan []byte, batch.QueueSize),
+		done:      make(chan struct{}),
+	}
+	go hook.run()
+
+	return hook
+}
+
+// Fire func used by logrus to queue the log for the next batch, the entry is
+// dropped if the queue is full
+func (hook *LogrusHTTPHook) Fire(entry *logrus.Entry) error {
+	b, err := hook.formatter.Format(entry)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	hook.mu.RLock()
+	defer hook.mu.RUnlock()
+
+	if hook.closed {
+		atomic.AddUint64(&hook.dropped, 1)
+		return nil
+	}
+
+	select {
+	case hook.queue <- bytes.TrimRight(b, "\n"):
+	default:
+		atomic.AddUint64(&hook.dropped, 1)
+	}
+	return nil
+}
+
+// Levels defines in which log levels the HTTP hook works
+func (hook *LogrusHTTPHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Dropped returns the number of entries dropped because the queue was full or
+// sending their batch failed
+func (hook *LogrusHTTPHook) Dropped() uint64 {
+	return atomic.LoadUint64(&hook.dropped)
+}
+
+// Close stops accepting entries and waits until the queued ones are sent
+func (hook *LogrusHTTPHook) Close() error {
+	hook.mu.Lock()
+	if !hook.closed {
+		hook.closed = true
+		close(hook.queue)
+	}
+	hook.mu.Unlock()
+
+	<-hook.done
+	return nil
+}
+
+// run collects the queued entries into batches until the queue is closed
+func (hook *LogrusHTTPHook) run() {
+	defer close(hook.done)
+
+	ticker := time.NewTicker(hook.batch.FlushInterval)
+	defer ticker.Stop()
+
+	entries := make([][]byte, 0, hook.batch.Size)
+	for {
+		select {
+		case entry, ok := <-hook.queue:
+			if !ok {
+				hook.flush(entries)
+				return
+			}
+			entries = append(entries, entry)
+			if len(entries) >= hook.batch.Size {
+				hook.flush(entries)
+				entries = entries[:0]
+			}
+		case <-ticker.C:
+			hook.flush(entries)
+			entries = entries[:0]
+		}
+	}
+}
+
+// flush sends the entries, retrying transient failures with backoff
+func (hook *LogrusHTTPHook) flush(entries [][]byte) {
+	if len(entries) == 0 {
+		return
+	}
+
+	body := append([]byte{'['}, bytes.Join(entries, []byte{','})...)
+	body = append(body, ']')
+
+	backoff := hook.batch.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := hook.send(body)
+		if err == nil {
+			return
+		}
+		if !retry || attempt >= hook.batch.MaxRetries {
+			atomic.AddUint64(&hook.dropped, uint64(len(entries)))
+			_, _ = fmt.Fprintf(os.Stderr, "unable to send log batch on httphook %v", err)
+			return
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// send posts the body once and reports whether a failure is worth retrying
+func (hook *LogrusHTTPHook) send(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, hook.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := hook.batch.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("unexpected status %s", res.Status)
+	return res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests, err
+}
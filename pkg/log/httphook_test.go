@@ -0,0 +1,169 @@
+package log
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector is a log collector which records the size of every batch and
+// fails the first requests with the given status
+type collector struct {
+	mu       sync.Mutex
+	failures int
+	status   int
+	requests int
+	batches  []int
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests++
+	if c.failures > 0 {
+		c.failures--
+		w.WriteHeader(c.status)
+		return
+	}
+	var batch []map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.batches = append(c.batches, len(batch))
+}
+
+func (c *collector) result() (requests int, batches []int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.requests, append([]int(nil), c.batches...)
+}
+
+func TestHTTPHookBatches(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	hook := NewHTTPHook(srv.URL, HTTPBatch{Size: 4, FlushInterval: time.Hour})
+	l := NewWithOptions(WithOutput(ioutil.Discard), WithHooks(hook))
+	for i := 0; i < 10; i++ {
+		l.Warnf("entry %d", i)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	_, batches := c.result()
+	want := []int{4, 4, 2}
+	if len(batches) != len(want) {
+		t.Fatalf("got batches %v, want %v", batches, want)
+	}
+	for i := range want {
+		if batches[i] != want[i] {
+			t.Errorf("got batches %v, want %v", batches, want)
+			break
+		}
+	}
+	if hook.Dropped() != 0 {
+		t.Errorf("Dropped() = %d, want 0", hook.Dropped())
+	}
+}
+
+func TestHTTPHookRetries(t *testing.T) {
+	c := &collector{failures: 2, status: http.StatusServiceUnavailable}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	hook := NewHTTPHook(srv.URL, HTTPBatch{Size: 10, MaxRetries: 3, RetryBackoff: time.Millisecond})
+	l := NewWithOptions(WithOutput(ioutil.Discard), WithHooks(hook))
+	l.Error("first")
+	l.Error("second")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	requests, batches := c.result()
+	if requests != 3 {
+		t.Errorf("sent %d requests, want 2 failed ones and 1 retry which succeeds", requests)
+	}
+	if len(batches) != 1 || batches[0] != 2 {
+		t.Errorf("got batches %v, want [2]", batches)
+	}
+	if hook.Dropped() != 0 {
+		t.Errorf("Dropped() = %d, want 0", hook.Dropped())
+	}
+}
+
+func TestHTTPHookDropsAfterRetries(t *testing.T) {
+	c := &collector{failures: 10, status: http.StatusInternalServerError}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	hook := NewHTTPHook(srv.URL, HTTPBatch{MaxRetries: 1, RetryBackoff: time.Millisecond})
+	l := NewWithOptions(WithOutput(ioutil.Discard), WithHooks(hook))
+	l.Error("lost")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if requests, _ := c.result(); requests != 2 {
+		t.Errorf("sent %d requests, want the first one and 1 retry", requests)
+	}
+	if hook.Dropped() != 1 {
+		t.Errorf("Dropped() = %d, want 1", hook.Dropped())
+	}
+}
+
+func TestHTTPHookNoRetryOnClientError(t *testing.T) {
+	c := &collector{failures: 10, status: http.StatusBadRequest}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	hook := NewHTTPHook(srv.URL, HTTPBatch{RetryBackoff: time.Millisecond})
+	l := NewWithOptions(WithOutput(ioutil.Discard), WithHooks(hook))
+	l.Error("rejected")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if requests, _ := c.result(); requests != 1 {
+		t.Errorf("sent %d requests, want 1 without retries", requests)
+	}
+}
+
+func TestHTTPHookCloseWithHangingCollector(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	defaults := NewHTTPHook(srv.URL, HTTPBatch{})
+	defer defaults.Close()
+	if defaults.batch.Client.Timeout != DefaultHTTPTimeout {
+		t.Errorf("default client timeout = %v, want %v", defaults.batch.Client.Timeout, DefaultHTTPTimeout)
+	}
+
+	client := &http.Client{Timeout: 20 * time.Millisecond}
+	hook := NewHTTPHook(srv.URL, HTTPBatch{Client: client, MaxRetries: -1})
+	l := NewWithOptions(WithOutput(ioutil.Discard), WithHooks(hook))
+	l.Error("stuck")
+
+	closed := make(chan error, 1)
+	go func() { closed <- l.Close() }()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked on a hanging collector")
+	}
+	if hook.Dropped() != 1 {
+		t.Errorf("Dropped() = %d, want 1", hook.Dropped())
+	}
+}
@@ -0,0 +1,80 @@
+package log
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ByteSize is a number of bytes, WithHumanizedValues renders it like "3.4MB"
+// in the text and logfmt formats while JSON keeps the raw number
+type ByteSize int64
+
+// byteUnits are the units of ByteSize in steps of 1000
+var byteUnits = []string{"KB", "MB", "GB", "TB", "PB", "EB"}
+
+// humanize renders the size with one decimal in the largest fitting unit
+func (b ByteSize) humanize() string {
+	if b < 1000 && b > -1000 {
+		return fmt.Sprintf("%dB", int64(b))
+	}
+	size := float64(b) / 1000
+	unit := 0
+	for (size >= 1000 || size <= -1000) && unit < len(byteUnits)-1 {
+		size /= 1000
+		unit++
+	}
+	return fmt.Sprintf("%.1f%s", size, byteUnits[unit])
+}
+
+// humanizeDuration rounds the duration to a tenth of its largest unit below a
+// minute, e.g. 1.2s or 3.4ms
+func humanizeDuration(d time.Duration) string {
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs >= time.Minute:
+		return d.Round(time.Second).String()
+	case abs >= time.Second:
+		return d.Round(100 * time.Millisecond).String()
+	case abs >= time.Millisecond:
+		return d.Round(100 * time.Microsecond).String()
+	case abs >= time.Microsecond:
+		return d.Round(100 * time.Nanosecond).String()
+	}
+	return d.String()
+}
+
+// humanizeValues returns the entry with durations and byte sizes in its fields
+// rendered readable. The entry is returned as is if it has none.
+func humanizeValues(entry *logrus.Entry) *logrus.Entry {
+	var data logrus.Fields
+	for k, v := range entry.Data {
+		var text string
+		switch v := v.(type) {
+		case time.Duration:
+			text = humanizeDuration(v)
+		case ByteSize:
+			text = v.humanize()
+		default:
+			continue
+		}
+		if data == nil {
+			data = make(logrus.Fields, len(entry.Data))
+			for key, value := range entry.Data {
+				data[key] = value
+			}
+		}
+		data[k] = text
+	}
+	if data == nil {
+		return entry
+	}
+
+	humanized := *entry
+	humanized.Data = data
+	return &humanized
+}
@@ -0,0 +1,57 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestHumanize(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{ByteSize(512), "512B"},
+		{ByteSize(3400000), "3.4MB"},
+		{ByteSize(-2500), "-2.5KB"},
+		{1234567 * time.Microsecond, "1.2s"},
+		{3456 * time.Microsecond, "3.5ms"},
+		{90*time.Second + 400*time.Millisecond, "1m30s"},
+		{250 * time.Nanosecond, "250ns"},
+	}
+	for _, tt := range tests {
+		var got string
+		switch v := tt.value.(type) {
+		case ByteSize:
+			got = v.humanize()
+		case time.Duration:
+			got = humanizeDuration(v)
+		}
+		if got != tt.want {
+			t.Errorf("humanize(%v) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestHumanizedValuesOnlyInText(t *testing.T) {
+	fields := Fields{"size": ByteSize(3400000), "took": 1234567 * time.Microsecond}
+
+	var text, logfmt, js bytes.Buffer
+	NewWithOptions(WithOutput(&text), WithColors(false), WithHumanizedValues()).WithFields(fields).Info("uploaded")
+	NewWithOptions(WithOutput(&logfmt), WithFormat(FormatLogfmt), WithHumanizedValues()).WithFields(fields).Info("uploaded")
+	NewWithOptions(WithOutput(&js), WithFormat(FormatJSON), WithHumanizedValues()).WithFields(fields).Info("uploaded")
+
+	for name, out := range map[string][]byte{"text": text.Bytes(), "logfmt": logfmt.Bytes()} {
+		if !bytes.Contains(out, []byte("size=3.4MB")) || !bytes.Contains(out, []byte("took=1.2s")) {
+			t.Errorf("%s values aren't humanized: %q", name, out)
+		}
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(js.Bytes(), &entry); err != nil {
+		t.Fatalf("output isn't JSON: %q: %v", js.Bytes(), err)
+	}
+	if entry["size"] != float64(3400000) || entry["took"] != float64(1234567000) {
+		t.Errorf("JSON values aren't raw numbers: %v", entry)
+	}
+}
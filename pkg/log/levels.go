@@ -0,0 +1,70 @@
+package log
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// levelState holds the levels shared by all loggers derived from the same
+// root. The underlying logrus logger is set to the trace level once and every
+// logger checks its effective level before emitting an entry, so prefix and
+// context overrides never change the level of other loggers.
+type levelState struct {
+	mu       sync.RWMutex
+	global   logrus.Level
+	prefixes map[string]logrus.Level
+}
+
+// newLevelState returns the level state of the logrus logger, taking over its
+// level as the global one
+func newLevelState(logger *logrus.Logger) *levelState {
+	s := &levelState{
+		global:   logger.GetLevel(),
+		prefixes: make(map[string]logrus.Level),
+	}
+	logger.SetLevel(logrus.TraceLevel)
+	return s
+}
+
+// setGlobal changes the level used for prefixes without override
+func (s *levelState) setGlobal(level logrus.Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.global = level
+}
+
+// setPrefix overrides the level of the prefix and all prefixes nested below it
+func (s *levelState) setPrefix(prefix string, level logrus.Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prefixes[prefix] = level
+}
+
+// removePrefix removes the override of the prefix
+func (s *levelState) removePrefix(prefix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.prefixes, prefix)
+}
+
+// effective returns the level of a logger with the given prefix, which is the
+// override of the longest matching prefix or the global level
+func (s *levelState) effective(prefix string) logrus.Level {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	level := s.global
+	matched := -1
+	for p, lvl := range s.prefixes {
+		if len(p) > matched && (prefix == p || strings.HasPrefix(prefix, p+prefixSeparator)) {
+			level = lvl
+			matched = len(p)
+		}
+	}
+	return level
+}
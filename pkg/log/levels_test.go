@@ -0,0 +1,141 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrefixLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		enabled bool
+	}{
+		{"match", "db", true},
+		{"nested below override", "db.pool", true},
+		{"no match falls back to global", "http", false},
+		{"similar name doesn't match", "dbx", false},
+	}
+
+	l, logs := NewObserver()
+	l.SetLevel(LevelInfo)
+	l.SetPrefixLevel("db", LevelDebug)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			var pl Logger = l
+			for _, p := range strings.Split(tt.prefix, prefixSeparator) {
+				pl = pl.WithPrefix(p)
+			}
+
+			if got := pl.Enabled(LevelDebug); got != tt.enabled {
+				t.Errorf("Enabled(debug) = %v, want %v", got, tt.enabled)
+			}
+			pl.Debug("query")
+			if got := logs.Len() == 1; got != tt.enabled {
+				t.Errorf("debug entry logged = %v, want %v", got, tt.enabled)
+			}
+		})
+	}
+}
+
+func TestNestedPrefixLevel(t *testing.T) {
+	l, logs := NewObserver()
+	l.SetLevel(LevelInfo)
+	l.SetPrefixLevel("db", LevelDebug)
+	l.SetPrefixLevel("db.pool", LevelWarn)
+
+	db := l.WithPrefix("db")
+	pool := db.WithPrefix("pool")
+
+	if got := db.Level(); got != LevelDebug {
+		t.Errorf("level of db = %q, want %q", got, LevelDebug)
+	}
+	if got := pool.Level(); got != LevelWarn {
+		t.Errorf("level of db.pool = %q, want the longest matching override %q", got, LevelWarn)
+	}
+	pool.Info("connection opened")
+	if logs.Len() != 0 {
+		t.Errorf("info entry of db.pool was logged below its warn override")
+	}
+
+	l.RemovePrefixLevel("db.pool")
+	if got := pool.Level(); got != LevelDebug {
+		t.Errorf("level of db.pool after removal = %q, want %q", got, LevelDebug)
+	}
+	l.RemovePrefixLevel("db")
+	if got := pool.Level(); got != LevelInfo {
+		t.Errorf("level of db.pool without overrides = %q, want %q", got, LevelInfo)
+	}
+}
+
+func TestFatalExitsWhenLevelDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	exits := 0
+	l := NewWithOptions(WithOutput(&buf), WithLevel(LevelPanic), WithExitFunc(func(int) {
+		exits++
+	}))
+
+	l.Fatal("global level")
+	l.SetLevel(LevelInfo)
+	l.SetPrefixLevel("db", LevelPanic)
+	l.WithPrefix("db").Fatalf("prefix %s", "level")
+	NewSampled(l.WithPrefix("db"), 1, 1, time.Minute).Fatalln("wrapped")
+
+	if exits != 3 {
+		t.Errorf("exit function called %d times, want 3", exits)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("disabled fatal entries were written: %q", buf.String())
+	}
+}
+
+func TestSetLevelAffectsTree(t *testing.T) {
+	root, logs := NewObserver()
+	root.SetLevel(LevelInfo)
+	child := root.WithFields(Fields{"service": "users"})
+	sibling := root.WithPrefix("db")
+
+	child.SetLevel(LevelDebug)
+
+	for name, l := range map[string]Logger{"root": root, "child": child, "sibling": sibling} {
+		if got := l.Level(); got != LevelDebug {
+			t.Errorf("level of %s = %q, want %q", name, got, LevelDebug)
+		}
+	}
+	sibling.Debug("query")
+	if logs.Len() != 1 {
+		t.Errorf("debug entry of a sibling wasn't logged after SetLevel on the child")
+	}
+
+	root.SetLevel("verbose")
+	if got := child.Level(); got != LevelDebug {
+		t.Errorf("invalid level changed the level to %q", got)
+	}
+}
+
+func TestLevelOrdering(t *testing.T) {
+	ordered := []Level{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal, LevelPanic}
+	for i, a := range ordered {
+		for j, b := range ordered {
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := Compare(a, b); got != want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", a, b, got, want)
+			}
+			if got := a.AtLeast(b); got != (i >= j) {
+				t.Errorf("%s.AtLeast(%s) = %t", a, b, got)
+			}
+		}
+	}
+	if got := Compare(Level("verbose"), LevelTrace); got != -1 {
+		t.Errorf("unknown level compared as %d to trace, want -1", got)
+	}
+}
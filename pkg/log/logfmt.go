@@ -0,0 +1,99 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+)
+
+// logfmtFormatter renders entries as logfmt key=value pairs
+type logfmtFormatter struct {
+	// Timestamp Format to use for display of the time key.
+	TimestampFormat string
+	// Expand nested maps and structs into dotted keys up to this depth, zero
+	// disables expanding.
+	FlattenDepth int
+	// Render durations and byte sizes readable, like 1.2s and 3.4MB.
+	HumanizeValues bool
+}
+
+// Format func used by logrus to format the log
+func (f *logfmtFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	entry = flattenGroups(entry, f.FlattenDepth)
+	entry = renameClashes(entry)
+	if f.HumanizeValues {
+		entry = humanizeValues(entry)
+	}
+	b := entry.Buffer
+	if b == nil {
+		b = &bytes.Buffer{}
+	}
+
+	timestampFormat := f.TimestampFormat
+	if timestampFormat == "" {
+		timestampFormat = defaultTimestampFormat
+	}
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	writeLogfmt(b, "time", entry.Time.Format(timestampFormat))
+	writeLogfmt(b, "level", string(fromLogrusLevel(entry.Level)))
+	writeLogfmt(b, "msg", entry.Message)
+	for _, k := range keys {
+		writeLogfmt(b, k, entry.Data[k])
+	}
+	if entry.HasCaller() {
+		function, file := callerPrettyfier(entry.Caller)
+		if function != "" {
+			writeLogfmt(b, "func", function)
+		}
+		writeLogfmt(b, "file", file)
+	}
+
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+// writeLogfmt appends a key=value pair, separated by a space from a previous one
+func writeLogfmt(b *bytes.Buffer, key string, value interface{}) {
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	b.WriteString(key)
+	b.WriteByte('=')
+
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case error:
+		text = v.Error()
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	if logfmtNeedsQuoting(text) {
+		b.WriteString(strconv.Quote(text))
+	} else {
+		b.WriteString(text)
+	}
+}
+
+// logfmtNeedsQuoting reports whether a value must be quoted to be parsed back
+func logfmtNeedsQuoting(text string) bool {
+	if text == "" {
+		return true
+	}
+	return strings.IndexFunc(text, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r)
+	}) >= 0
+}
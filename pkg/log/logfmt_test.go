@@ -0,0 +1,37 @@
+package log
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLogfmtQuoting(t *testing.T) {
+	f := &logfmtFormatter{TimestampFormat: time.RFC3339}
+	entry := &logrus.Entry{
+		Time:    time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Level:   logrus.InfoLevel,
+		Message: "user logged in",
+		Data: logrus.Fields{
+			"empty":  "",
+			"eq":     "a=b",
+			"err":    errors.New("not found"),
+			"plain":  "alice",
+			"quote":  `say "hi"`,
+			"status": 200,
+			"tab":    "a\tb",
+		},
+	}
+
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	want := `time=2024-03-01T12:00:00Z level=info msg="user logged in" empty="" eq="a=b" err="not found" ` +
+		`plain=alice quote="say \"hi\"" status=200 tab="a\tb"` + "\n"
+	if string(b) != want {
+		t.Errorf("got  %s\nwant %s", b, want)
+	}
+}
@@ -1,17 +1,51 @@
 package log
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"runtime"
+	"strings"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// ErrorKey field the error message is logged under by WithError
+	ErrorKey = "error"
+	// ErrorCauseKey field the root cause of a wrapped error is logged under
+	ErrorCauseKey = "error_cause"
+	// ErrorCodeKey field the code of an error is logged under by WithCode and
+	// WithError
+	ErrorCodeKey = "error_code"
+)
+
+// prefixSeparator joins the prefixes of nested loggers
+const prefixSeparator = "."
+
+// reservedKeyPrefix is prepended to field keys which collide with reservedKeys
+const reservedKeyPrefix = "fields."
+
+// reservedKeys are the keys of the metadata of an entry, WithFields renames
+// fields using them
+var reservedKeys = []string{"time", "level", "msg", "prefix"}
+
+// reservedKeyWarning makes sure renamed reserved keys are reported only once
+var reservedKeyWarning sync.Once
+
+// timestampFormat is the default layout used for timestamps
+const timestampFormat = "2006-01-02 15:04:05.000000"
+
 // Level type
 type Level string
 
 const (
+	// LevelTrace finer-grained informational events than debug
+	LevelTrace Level = "trace"
 	// LevelDebug usually only enabled when debugging
 	LevelDebug Level = "debug"
 	// LevelInfo general operational entries about what's going on inside the application
@@ -32,16 +66,108 @@ func (l *Level) String() string {
 	return string(*l)
 }
 
-// ParseLevel takes a string level and returns the Level constant
+// Set parses the given string into the Level, together with String and Type it
+// implements flag.Value and pflag.Value:
+//
+//	level := log.LevelInfo
+//	flag.Var(&level, "log-level", "minimum level of log entries")
+func (l *Level) Set(value string) error {
+	level, err := ParseLevel(value)
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// Type returns the type name shown in pflag usage messages
+func (l *Level) Type() string {
+	return "level"
+}
+
+// MarshalJSON encodes the Level as its canonical string
+func (l Level) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(l))
+}
+
+// UnmarshalJSON decodes and validates a Level from a JSON string
+func (l *Level) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("log level must be a string: %v", err)
+	}
+	return l.Set(value)
+}
+
+// MarshalYAML encodes the Level as its canonical string
+func (l Level) MarshalYAML() (interface{}, error) {
+	return string(l), nil
+}
+
+// UnmarshalYAML decodes and validates a Level from a YAML string
+func (l *Level) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err != nil {
+		return fmt.Errorf("log level must be a string: %v", err)
+	}
+	return l.Set(value)
+}
+
+// Severity returns the numeric rank of a Level, higher values are more severe.
+// Unknown levels return -1.
+func (l Level) Severity() int {
+	switch l {
+	case LevelTrace:
+		return 0
+	case LevelDebug:
+		return 1
+	case LevelInfo:
+		return 2
+	case LevelWarn:
+		return 3
+	case LevelError:
+		return 4
+	case LevelFatal:
+		return 5
+	case LevelPanic:
+		return 6
+	default:
+		return -1
+	}
+}
+
+// Compare orders levels by severity, it returns -1 if a is less severe than b,
+// 0 if both are equal and +1 if a is more severe than b
+func Compare(a, b Level) int {
+	switch sa, sb := a.Severity(), b.Severity(); {
+	case sa < sb:
+		return -1
+	case sa > sb:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// AtLeast reports whether l is at least as severe as level
+func (l Level) AtLeast(level Level) bool {
+	return Compare(l, level) >= 0
+}
+
+// ParseLevel takes a string level and returns the Level constant, the aliases
+// "warning" and "err" are accepted for LevelWarn and LevelError. Matching is
+// case-insensitive.
 func ParseLevel(level string) (Level, error) {
-	switch level {
+	switch strings.ToLower(level) {
+	case "trace":
+		return LevelTrace, nil
 	case "debug":
 		return LevelDebug, nil
 	case "info":
 		return LevelInfo, nil
-	case "warn":
+	case "warn", "warning":
 		return LevelWarn, nil
-	case "error":
+	case "error", "err":
 		return LevelError, nil
 	case "fatal":
 		return LevelFatal, nil
@@ -52,10 +178,60 @@ func ParseLevel(level string) (Level, error) {
 	}
 }
 
+// Format type
+type Format string
+
+const (
+	// FormatText human readable, optionally colored output
+	FormatText Format = "text"
+	// FormatJSON one JSON object per entry, suitable for log aggregators
+	FormatJSON Format = "json"
+	// FormatLogfmt key=value pairs per entry, as parsed by e.g. Loki
+	FormatLogfmt Format = "logfmt"
+	// FormatECS JSON following the Elastic Common Schema
+	FormatECS Format = "ecs"
+	// FormatGELF JSON following the Graylog Extended Log Format
+	FormatGELF Format = "gelf"
+	// FormatDatadog JSON with the attribute names Datadog recognizes
+	FormatDatadog Format = "datadog"
+	// FormatCloudWatch flat JSON with epoch millisecond timestamps for
+	// CloudWatch Logs Insights
+	FormatCloudWatch Format = "cloudwatch"
+	// FormatCSV comma separated rows of time, level, msg and the columns set
+	// through WithCSVColumns, for spreadsheets and data frames
+	FormatCSV Format = "csv"
+	// FormatTSV like FormatCSV, separated by tabs
+	FormatTSV Format = "tsv"
+)
+
+// ParseFormat takes a string format and returns the Format constant
+func ParseFormat(format string) (Format, error) {
+	switch format {
+	case "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	case "logfmt":
+		return FormatLogfmt, nil
+	case "ecs":
+		return FormatECS, nil
+	case "gelf":
+		return FormatGELF, nil
+	case "datadog":
+		return FormatDatadog, nil
+	case "cloudwatch":
+		return FormatCloudWatch, nil
+	case "csv":
+		return FormatCSV, nil
+	case "tsv":
+		return FormatTSV, nil
+	default:
+		return "", fmt.Errorf("%v: %s", ErrUnknownFormat, format)
+	}
+}
+
 // Logger implementation is responsible for providing structured and leveled
 // logging functions.
-// Logger implementation is responsible for providing structured and levled
-// logging functions.
 type Logger interface {
 	Debug(args ...interface{})
 	Debugln(args ...interface{})
@@ -68,7 +244,11 @@ type Logger interface {
 	Warnf(msg string, args ...interface{})
 	Error(msg string)
 	Errorf(msg string, args ...interface{})
+	Errorln(...interface{})
+	Fatal(msg string)
 	Fatalf(msg string, args ...interface{})
+	Fatalln(...interface{})
+	Panic(msg string)
 	Print(args ...interface{})
 	Printf(msg string, args ...interface{})
 	Println(...interface{})
@@ -79,11 +259,63 @@ type Logger interface {
 
 	// WithFields should return a logger which is annotated with the given
 	// fields. These fields should be added to every logging call on the
-	// returned logger.
+	// returned logger. Fields named like the time, level, msg or prefix of an
+	// entry are renamed with the "fields." prefix.
 	WithFields(m map[string]interface{}) Logger
+	// With should return a logger annotated with the typed fields, e.g.
+	// String("user", name) or Int("status", code). It is the cheaper
+	// alternative to WithFields on hot paths.
+	With(fields ...Field) Logger
+	// WithStruct should return a logger annotated with the exported fields of
+	// the struct, named and filtered by their log tags, e.g.
+	// `log:"user_id,omitempty"`. Fields tagged `log:"name,sensitive"` are
+	// logged as RedactedValue and `log:"-"` skips a field.
+	WithStruct(v interface{}) Logger
 	WithPrefix(prefix string) Logger
+	// WithContext should return a logger annotated with the well known
+	// values found in the context, values that are not set are skipped.
+	WithContext(ctx context.Context) Logger
+	// WithGroup should return a logger which nests the fields of following
+	// WithFields calls in a group with the given name. Groups are rendered as
+	// nested objects in JSON and as dotted keys, e.g. "http.method", in the
+	// text, logfmt and GELF formats.
+	WithGroup(name string) Logger
+	// WithError should return a logger annotated with the error, a nil error
+	// returns the logger unchanged. The code of an error implementing
+	// interface{ Code() string } is added under ErrorCodeKey.
+	WithError(err error) Logger
+	// WithCode should return a logger annotated with the error code under
+	// ErrorCodeKey, for dashboards of the most frequent errors.
+	WithCode(code string) Logger
 
 	Level() Level
+	// SetLevel changes the level at runtime. Loggers derived through
+	// WithFields or WithPrefix share their level with the logger they were
+	// created from, so the change applies to the whole tree.
+	SetLevel(level Level)
+	// SetPrefixLevel overrides the level of loggers annotated with the prefix
+	// or a prefix nested below it, e.g. "db" also applies to "db.query".
+	// Like SetLevel it applies to the whole tree.
+	SetPrefixLevel(prefix string, level Level)
+	// RemovePrefixLevel removes the override set by SetPrefixLevel.
+	RemovePrefixLevel(prefix string)
+	// Enabled reports whether entries of the given level would be logged, it
+	// can be used to guard building expensive log arguments.
+	Enabled(level Level) bool
+	// StartTimer starts a Timer for the operation which logs its duration
+	// when stopped.
+	StartTimer(operation string) *Timer
+	// AddHook registers an additional hook, e.g. for error reporting. Hooks
+	// are registered on the underlying logger shared by every logger derived
+	// through WithFields or WithPrefix, so a hook added on a child logger
+	// fires for the whole tree. Hooks implementing io.Closer are closed by
+	// Close.
+	AddHook(hook logrus.Hook)
+	// Close flushes and closes the files the logger writes to. Callers should
+	// defer it right after creating the logger. Loggers derived through
+	// WithFields or WithPrefix share those files, closing one closes them
+	// for the whole tree.
+	Close() error
 }
 
 // Fields own declaration of logrus Fields
@@ -91,77 +323,665 @@ type Fields logrus.Fields
 
 // New returns a logger implemented using the logrus package.
 func New(wr io.Writer, level Level, file string) Logger {
-	if wr == nil {
-		wr = os.Stderr
+	return NewWithFormat(wr, level, file, FormatText)
+}
+
+// NewWithFormat returns a logger implemented using the logrus package which
+// renders its entries in the given format.
+func NewWithFormat(wr io.Writer, level Level, file string, format Format) Logger {
+	return NewWithOptions(WithOutput(wr), WithLevel(level), WithFile(file), WithFormat(format))
+}
+
+// NewWithOptions returns a logger implemented using the logrus package which
+// is configured by the given options.
+func NewWithOptions(opts ...Option) Logger {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(cfg)
 	}
 
 	lg := logrus.New()
-	lg.Out = wr
+	lg.Out = cfg.output
+	if cfg.exitFunc != nil {
+		lg.ExitFunc = cfg.exitFunc
+	}
 
-	lvl, err := logrus.ParseLevel(level.String())
+	lvl, err := logrus.ParseLevel(cfg.level.String())
 	if err != nil {
 		lvl = logrus.WarnLevel
-		lg.Warnf("failed to parse log-level '%s', defaulting to 'warning'", level)
+		lg.Warnf("failed to parse log-level '%s', defaulting to 'warning'", cfg.level)
 	}
 	lg.SetLevel(lvl)
-	lg.SetFormatter(getFormatter(false))
+	lg.SetFormatter(cfg.getFormatter(false))
+	lg.Hooks.Add(statsHook{})
+
+	if cfg.clock != nil || cfg.utc {
+		lg.Hooks.Add(&timeHook{clock: cfg.clock, utc: cfg.utc})
+	}
+
+	if len(cfg.redact) > 0 {
+		lg.Hooks.Add(newRedactHook(cfg.redact))
+	}
+
+	if len(cfg.scrub) > 0 {
+		lg.Hooks.Add(&scrubHook{patterns: cfg.scrub})
+	}
+
+	if cfg.caller {
+		lg.SetReportCaller(true)
+		lg.Hooks.Add(&callerHook{withFunction: cfg.callerFn})
+	}
+
+	if cfg.stack {
+		lg.Hooks.Add(&stackHook{})
+	}
+
+	if cfg.outLevel != "" {
+		// the primary output becomes a writer hook filtering by its own level,
+		// logrus itself writes nothing
+		primary := Output{Writer: cfg.output, Level: cfg.outLevel}
+		lg.Hooks.Add(newWriterHook(primary, cfg.getOutputFormatter(primary)))
+		lg.Out = io.Discard
+		lg.SetFormatter(discardFormatter{})
+	}
+
+	for _, output := range cfg.outputs {
+		if output.Writer != nil {
+			lg.Hooks.Add(newWriterHook(output, cfg.getOutputFormatter(output)))
+		}
+	}
+
+	for _, hook := range cfg.hooks {
+		lg.Hooks.Add(hook)
+	}
 
-	if file != "" {
-		fileHook, err := NewLogrusFileHook(file, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0666)
+	if cfg.errOutput != nil {
+		lg.Hooks.Add(newWriterHook(Output{Writer: cfg.errOutput, Level: LevelError}, cfg.getFormatter(true)))
+	}
+
+	if cfg.file != "" {
+		fileHook, err := NewLogrusRotatingFileHook(cfg.file, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0666, cfg.rotation)
 		if err == nil {
+			fileHook.formatter = cfg.getFormatter(true)
 			lg.Hooks.Add(fileHook)
 		} else {
 			lg.Warnf("Failed to open logfile, using standard out: %v", err)
 		}
 	}
 
+	if cfg.syslog != nil {
+		syslogHook, err := NewSyslogHook(cfg.syslog.network, cfg.syslog.addr, cfg.syslog.tag)
+		if err == nil {
+			lg.Hooks.Add(syslogHook)
+		} else {
+			lg.Warnf("Failed to connect to syslog, using standard out: %v", err)
+		}
+	}
+
 	return &logrusLogger{
-		Entry: logrus.NewEntry(lg),
+		Entry:  logrus.NewEntry(lg),
+		config: cfg,
+		levels: newLevelState(lg),
 	}
 }
 
 // logrusLogger provides functions for structured logging.
 type logrusLogger struct {
 	*logrus.Entry
+	config *config
+	levels *levelState
+	group  []string
+}
+
+// derivedLogger holds a derived logger together with its entry, so deriving a
+// logger takes a single allocation for both
+type derivedLogger struct {
+	logger logrusLogger
+	entry  logrus.Entry
+}
+
+// derive returns a logger for the field map and context sharing the state of
+// l. The field map is shared with the returned logger and must not be
+// modified afterwards.
+func (l *logrusLogger) derive(data logrus.Fields, ctx context.Context) *logrusLogger {
+	d := &derivedLogger{
+		entry: logrus.Entry{
+			Logger:  l.Entry.Logger,
+			Data:    data,
+			Time:    l.Entry.Time,
+			Context: ctx,
+		},
+	}
+	d.logger = logrusLogger{
+		Entry:  &d.entry,
+		config: l.config,
+		levels: l.levels,
+		group:  l.group,
+	}
+	return &d.logger
+}
+
+// extend returns a copy of the field map of l with room for n more fields
+func (l *logrusLogger) extend(n int) logrus.Fields {
+	data := make(logrus.Fields, len(l.Entry.Data)+n)
+	for k, v := range l.Entry.Data {
+		data[k] = v
+	}
+	return data
 }
 
-// Level returns the Level that set on the Logger
+// prefix returns the prefix the logger is annotated with
+func (l *logrusLogger) prefix() string {
+	prefix, _ := l.Entry.Data["prefix"].(string)
+	return prefix
+}
+
+// Level returns the effective Level of the Logger, taking prefix and context
+// overrides into account
 func (l *logrusLogger) Level() Level {
-	level, _ := ParseLevel(l.Entry.Logger.Level.String())
-	return level
+	return fromLogrusLevel(l.effective())
+}
+
+// effective returns the level of the context the logger is bound to if it
+// carries one, the level of its prefix otherwise
+func (l *logrusLogger) effective() logrus.Level {
+	if level, ok := LevelFromContext(l.Entry.Context); ok {
+		if lvl, err := logrus.ParseLevel(level.String()); err == nil {
+			return lvl
+		}
+	}
+	return l.levels.effective(l.prefix())
+}
+
+// SetLevel changes the level which is shared by all loggers derived from the
+// same root
+func (l *logrusLogger) SetLevel(level Level) {
+	lvl, err := logrus.ParseLevel(level.String())
+	if err != nil {
+		l.Warnf("failed to parse log-level '%s', keeping '%s'", level, l.Level())
+		return
+	}
+	l.levels.setGlobal(lvl)
+}
+
+// SetPrefixLevel overrides the level of loggers with the given prefix or a
+// prefix nested below it, shared by all loggers derived from the same root
+func (l *logrusLogger) SetPrefixLevel(prefix string, level Level) {
+	lvl, err := logrus.ParseLevel(level.String())
+	if err != nil {
+		l.Warnf("failed to parse log-level '%s' for prefix '%s'", level, prefix)
+		return
+	}
+	l.levels.setPrefix(prefix, lvl)
+}
+
+// RemovePrefixLevel removes the level override of the prefix
+func (l *logrusLogger) RemovePrefixLevel(prefix string) {
+	l.levels.removePrefix(prefix)
+}
+
+// Enabled reports whether entries of the given level would be logged
+func (l *logrusLogger) Enabled(level Level) bool {
+	lvl, err := logrus.ParseLevel(level.String())
+	if err != nil {
+		return false
+	}
+	return lvl <= l.effective()
+}
+
+// StartTimer starts a timer taking the time from the configured clock
+func (l *logrusLogger) StartTimer(operation string) *Timer {
+	return newTimer(l, l.config.clock, operation)
+}
+
+// AddHook registers the hook on the underlying logrus logger
+func (l *logrusLogger) AddHook(hook logrus.Hook) {
+	l.Entry.Logger.AddHook(hook)
+}
+
+// Close closes every hook of the underlying logrus logger that holds resources
+func (l *logrusLogger) Close() error {
+	var err error
+	closed := make(map[io.Closer]bool)
+	for _, hooks := range l.Entry.Logger.Hooks {
+		for _, hook := range hooks {
+			closer, ok := hook.(io.Closer)
+			if !ok || closed[closer] {
+				continue
+			}
+			closed[closer] = true
+			if closeErr := closer.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}
+	}
+	return err
 }
 
 // WithFields should return a logger which is annotated with the given fields
 func (l *logrusLogger) WithFields(fields map[string]interface{}) Logger {
-	annotatedEntry := l.Entry.WithFields(fields)
-	return &logrusLogger{
-		Entry: annotatedEntry,
+	if len(l.group) > 0 {
+		data := l.extend(1)
+		group, _ := data[l.group[0]].(Fields)
+		data[l.group[0]] = mergeGroup(group, l.group[1:], fields)
+		return l.derive(data, l.Entry.Context)
+	}
+
+	data := l.extend(len(fields))
+	for k, v := range fields {
+		data[l.fieldKey(k)] = v
+	}
+	return l.derive(data, l.Entry.Context)
+}
+
+// fieldKey returns the key with the "fields." prefix if it collides with the
+// metadata of an entry, so it can't corrupt the output
+func (l *logrusLogger) fieldKey(key string) string {
+	if !isReserved(key) {
+		return key
+	}
+
+	reservedKeyWarning.Do(func() {
+		if l.Enabled(LevelDebug) {
+			l.Entry.Debugf("fields with reserved keys %v are renamed with the prefix %q", reservedKeys, reservedKeyPrefix)
+		}
+	})
+	return reservedKeyPrefix + key
+}
+
+// With returns a logger annotated with the typed fields. Unlike WithFields it
+// needs no intermediate map, the fields are copied straight into the field map
+// of the entry.
+func (l *logrusLogger) With(fields ...Field) Logger {
+	if len(fields) == 0 {
+		return l
+	}
+	if len(l.group) > 0 {
+		return l.WithFields(fieldMap(fields))
 	}
+
+	data := l.extend(len(fields))
+	for _, field := range fields {
+		if field.Type != SkipType {
+			data[l.fieldKey(field.Key)] = field.Value()
+		}
+	}
+	return l.derive(data, l.Entry.Context)
+}
+
+// WithStruct returns a logger which is annotated with the exported fields of
+// the struct
+func (l *logrusLogger) WithStruct(v interface{}) Logger {
+	return l.WithFields(structFields(v))
+}
+
+// WithGroup returns a logger which nests the fields of following WithFields
+// calls in a group with the given name
+func (l *logrusLogger) WithGroup(name string) Logger {
+	if name == "" {
+		return l
+	}
+
+	logger := l.derive(l.Entry.Data, l.Entry.Context)
+	logger.group = append(l.group[:len(l.group):len(l.group)], name)
+	return logger
+}
+
+func (l *logrusLogger) entryContext() context.Context {
+	return l.Entry.Context
+}
+
+func (l *logrusLogger) fields() Fields {
+	fields := make(Fields, len(l.Entry.Data))
+	for k, v := range l.Entry.Data {
+		fields[k] = v
+	}
+	return fields
+}
+
+// WithContext should return a logger which is annotated with the configured
+// context values
+func (l *logrusLogger) WithContext(ctx context.Context) Logger {
+	fields := contextFields(ctx, l.config.ctxFields, l.config.ctxExtract)
+	data := l.extend(len(fields))
+	for k, v := range fields {
+		data[l.fieldKey(k)] = v
+	}
+	return l.derive(data, ctx)
+}
+
+// WithError should return a logger which is annotated with the error message
+// and, for wrapped errors, the message of the root cause
+func (l *logrusLogger) WithError(err error) Logger {
+	if err == nil {
+		return l
+	}
+
+	data := l.extend(3)
+	data[ErrorKey] = err.Error()
+	if cause := rootCause(err); cause != err {
+		data[ErrorCauseKey] = cause.Error()
+	}
+	var coded interface{ Code() string }
+	if errors.As(err, &coded) && coded.Code() != "" {
+		data[ErrorCodeKey] = coded.Code()
+	}
+	return l.derive(data, l.Entry.Context)
+}
+
+// WithCode returns a logger which is annotated with the error code
+func (l *logrusLogger) WithCode(code string) Logger {
+	data := l.extend(1)
+	data[ErrorCodeKey] = code
+	return l.derive(data, l.Entry.Context)
 }
 
-// WithPrefix should return a logger which is annotated with the given prefix
+// WithPrefix should return a logger which is annotated with the given prefix,
+// it is appended to an existing prefix separated by a dot
 func (l *logrusLogger) WithPrefix(prefix string) Logger {
-	return l.WithFields(Fields{"prefix": prefix})
+	if parent, ok := l.Entry.Data["prefix"].(string); ok && parent != "" {
+		prefix = parent + prefixSeparator + prefix
+	}
+	data := l.extend(1)
+	data["prefix"] = prefix
+	return l.derive(data, l.Entry.Context)
 }
 
-func (ll *logrusLogger) Error(msg string) {
-	ll.Errorf(msg)
+func (ll *logrusLogger) Trace(args ...interface{}) {
+	if ll.Enabled(LevelTrace) {
+		defer ll.recoverLog()
+		ll.Entry.Trace(args...)
+	}
+}
+
+func (ll *logrusLogger) Traceln(args ...interface{}) {
+	if ll.Enabled(LevelTrace) {
+		defer ll.recoverLog()
+		ll.Entry.Traceln(args...)
+	}
+}
+
+func (ll *logrusLogger) Tracef(msg string, args ...interface{}) {
+	if ll.Enabled(LevelTrace) {
+		defer ll.recoverLog()
+		ll.Entry.Tracef(msg, args...)
+	}
+}
+
+func (ll *logrusLogger) Debug(args ...interface{}) {
+	if ll.Enabled(LevelDebug) {
+		defer ll.recoverLog()
+		ll.Entry.Debug(args...)
+	}
+}
+
+func (ll *logrusLogger) Debugln(args ...interface{}) {
+	if ll.Enabled(LevelDebug) {
+		defer ll.recoverLog()
+		ll.Entry.Debugln(args...)
+	}
+}
+
+func (ll *logrusLogger) Debugf(msg string, args ...interface{}) {
+	if ll.Enabled(LevelDebug) {
+		defer ll.recoverLog()
+		ll.Entry.Debugf(msg, args...)
+	}
 }
 
 func (ll *logrusLogger) Info(msg string) {
 	ll.Infof(msg)
 }
 
-func (ll *logrusLogger) Print(args ...interface{}) {
-	ll.Debug(args...)
+func (ll *logrusLogger) Infoln(args ...interface{}) {
+	if ll.Enabled(LevelInfo) {
+		defer ll.recoverLog()
+		ll.Entry.Infoln(args...)
+	}
+}
+
+func (ll *logrusLogger) Infof(msg string, args ...interface{}) {
+	if ll.Enabled(LevelInfo) {
+		defer ll.recoverLog()
+		ll.Entry.Infof(msg, args...)
+	}
 }
 
 func (ll *logrusLogger) Warn(msg string) {
 	ll.Warnf(msg)
 }
 
+func (ll *logrusLogger) Warnln(args ...interface{}) {
+	if ll.Enabled(LevelWarn) {
+		defer ll.recoverLog()
+		ll.Entry.Warnln(args...)
+	}
+}
+
+func (ll *logrusLogger) Warnf(msg string, args ...interface{}) {
+	if ll.Enabled(LevelWarn) {
+		defer ll.recoverLog()
+		ll.Entry.Warnf(msg, args...)
+	}
+}
+
+func (ll *logrusLogger) Error(msg string) {
+	ll.Errorf(msg)
+}
+
+func (ll *logrusLogger) Errorln(args ...interface{}) {
+	if ll.Enabled(LevelError) {
+		defer ll.recoverLog()
+		ll.Entry.Errorln(args...)
+	}
+}
+
+func (ll *logrusLogger) Errorf(msg string, args ...interface{}) {
+	if ll.Enabled(LevelError) {
+		defer ll.recoverLog()
+		ll.Entry.Errorf(msg, args...)
+	}
+}
+
+func (ll *logrusLogger) Fatal(msg string) {
+	defer ll.Entry.Logger.Exit(1)
+	if ll.Enabled(LevelFatal) {
+		defer ll.recoverLog()
+		ll.Entry.Log(logrus.FatalLevel, msg)
+	}
+}
+
+func (ll *logrusLogger) Fatalln(args ...interface{}) {
+	defer ll.Entry.Logger.Exit(1)
+	if ll.Enabled(LevelFatal) {
+		defer ll.recoverLog()
+		ll.Entry.Logln(logrus.FatalLevel, args...)
+	}
+}
+
+func (ll *logrusLogger) Fatalf(msg string, args ...interface{}) {
+	defer ll.Entry.Logger.Exit(1)
+	if ll.Enabled(LevelFatal) {
+		defer ll.recoverLog()
+		ll.Entry.Logf(logrus.FatalLevel, msg, args...)
+	}
+}
+
+func (ll *logrusLogger) Panic(msg string) {
+	if ll.Enabled(LevelPanic) {
+		defer ll.recoverLog()
+		ll.Entry.Panic(msg)
+	}
+}
+
+func (ll *logrusLogger) Print(args ...interface{}) {
+	ll.Debug(args...)
+}
+
+func (ll *logrusLogger) Println(args ...interface{}) {
+	if ll.Enabled(LevelInfo) {
+		defer ll.recoverLog()
+		ll.Entry.Println(args...)
+	}
+}
+
+func (ll *logrusLogger) Printf(msg string, args ...interface{}) {
+	if ll.Enabled(LevelInfo) {
+		defer ll.recoverLog()
+		ll.Entry.Printf(msg, args...)
+	}
+}
+
 func (ll *logrusLogger) Verbose() bool {
-	return ll.Entry.Logger.GetLevel().String() == "debug"
+	return ll.effective() == logrus.DebugLevel
+}
+
+// recoverLog recovers a panic raised while logging an entry, e.g. by a field
+// whose MarshalJSON or a hook panics, so logging never crashes the caller. The
+// failure is logged at error level without the fields of the entry instead,
+// or written to stderr if that fails as well. The panic of Panic is passed on.
+func (ll *logrusLogger) recoverLog() {
+	rec := recover()
+	if rec == nil {
+		return
+	}
+	if _, ok := rec.(*logrus.Entry); ok {
+		panic(rec)
+	}
+
+	defer func() {
+		if recover() != nil {
+			fmt.Fprintf(os.Stderr, "failed to log entry: %v\n", rec)
+		}
+	}()
+	fields := logrus.Fields{ErrorKey: fmt.Sprint(rec)}
+	if prefix := ll.prefix(); prefix != "" {
+		fields["prefix"] = prefix
+	}
+	logrus.NewEntry(ll.Entry.Logger).WithFields(fields).Error("failed to log entry, it was dropped")
+}
+
+// logAt logs an already rendered message at the given level
+func logAt(l Logger, level Level, msg string) {
+	switch level {
+	case LevelTrace:
+		l.Tracef("%s", msg)
+	case LevelDebug:
+		l.Debugf("%s", msg)
+	case LevelInfo:
+		l.Infof("%s", msg)
+	case LevelWarn:
+		l.Warnf("%s", msg)
+	case LevelFatal:
+		l.Fatal(msg)
+	case LevelPanic:
+		l.Panic(msg)
+	default:
+		l.Errorf("%s", msg)
+	}
+}
+
+// rootCause unwraps err until it reaches the innermost error
+func rootCause(err error) error {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
+
+// fromLogrusLevel maps a logrus level to the matching Level constant
+func fromLogrusLevel(level logrus.Level) Level {
+	switch level {
+	case logrus.TraceLevel:
+		return LevelTrace
+	case logrus.DebugLevel:
+		return LevelDebug
+	case logrus.InfoLevel:
+		return LevelInfo
+	case logrus.WarnLevel:
+		return LevelWarn
+	case logrus.ErrorLevel:
+		return LevelError
+	case logrus.FatalLevel:
+		return LevelFatal
+	default:
+		return LevelPanic
+	}
+}
+
+// getFormatter returns the formatter selected by the config, plain formatters
+// never use colors.
+func (c *config) getFormatter(plain bool) logrus.Formatter {
+	if c.formatter != nil {
+		return c.formatter
+	}
+
+	switch c.format {
+	case FormatJSON:
+		formatter := getJSONFormatter()
+		formatter.TimestampFormat = c.timeFormat
+		if c.levelKey != "" {
+			formatter.FieldMap = logrus.FieldMap{logrus.FieldKeyLevel: c.levelKey}
+		}
+		return formatter
+	case FormatLogfmt:
+		return &logfmtFormatter{TimestampFormat: c.timeFormat, FlattenDepth: c.flatten, HumanizeValues: c.humanize}
+	case FormatECS:
+		return &ecsFormatter{ServiceName: c.service}
+	case FormatGELF:
+		return newGELFFormatter(c.host, c.flatten)
+	case FormatCloudWatch:
+		flatten := c.flatten
+		if flatten <= 0 {
+			flatten = DefaultFlattenDepth
+		}
+		return &cloudWatchFormatter{LevelKey: c.levelKey, FlattenDepth: flatten}
+	case FormatDatadog:
+		return &datadogFormatter{Service: c.service, Env: c.env, Version: c.version}
+	case FormatCSV, FormatTSV:
+		formatter := &csvFormatter{TimestampFormat: c.timeFormat, Columns: c.csvColumns, PackFields: c.csvPack}
+		if c.format == FormatTSV {
+			formatter.Comma = '\t'
+		}
+		return formatter
+	}
+
+	formatter := getFormatter(plain)
+	formatter.TimestampFormat = c.timeFormat
+	formatter.FlattenDepth = c.flatten
+	formatter.Multiline = c.multiline
+	formatter.HumanizeValues = c.humanize
+	formatter.FieldColumnWidth = c.fieldWidth
+	if plain {
+		return formatter
+	}
+	if c.theme != nil {
+		formatter.setColorScheme(c.theme.scheme())
+	}
+	if c.colors != nil {
+		formatter.ForceColors = *c.colors
+		formatter.DisableColors = !*c.colors
+	} else {
+		formatter.ForceColors = false
+		formatter.DisableColors = os.Getenv(EnvNoColor) != ""
+	}
+	return formatter
+}
+
+// getJSONFormatter returns the formatter used for structured JSON output.
+func getJSONFormatter() *logrus.JSONFormatter {
+	return &logrus.JSONFormatter{
+		TimestampFormat:  timestampFormat,
+		CallerPrettyfier: callerPrettyfier,
+	}
+}
+
+// callerPrettyfier returns the function and file:line of a caller frame
+func callerPrettyfier(frame *runtime.Frame) (string, string) {
+	return frame.Function, fmt.Sprintf("%s:%d", frame.File, frame.Line)
 }
 
 // getFormatter returns the default log formatter.
@@ -173,7 +993,7 @@ func getFormatter(disableColors bool) *textFormatter {
 		DisableTimestamp: false,
 		FullTimestamp:    true,
 		DisableSorting:   true,
-		TimestampFormat:  "2006-01-02 15:04:05.000000",
+		TimestampFormat:  timestampFormat,
 		SpacePadding:     45,
 	}
 }
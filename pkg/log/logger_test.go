@@ -0,0 +1,330 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Level
+	}{
+		{"trace", LevelTrace},
+		{"debug", LevelDebug},
+		{"info", LevelInfo},
+		{"warn", LevelWarn},
+		{"warning", LevelWarn},
+		{"WARNING", LevelWarn},
+		{"error", LevelError},
+		{"err", LevelError},
+		{"Err", LevelError},
+		{"fatal", LevelFatal},
+		{"panic", LevelPanic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseLevel(tt.in)
+			if err != nil {
+				t.Fatalf("ParseLevel(%q) failed: %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLevel(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLevelUnknown(t *testing.T) {
+	for _, in := range []string{"", "verbose", "warnings"} {
+		if _, err := ParseLevel(in); err == nil {
+			t.Errorf("ParseLevel(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestFatalfCallsExitFunc(t *testing.T) {
+	var codes []int
+	l := NewWithOptions(WithOutput(ioutil.Discard), WithExitFunc(func(code int) {
+		codes = append(codes, code)
+	}))
+
+	l.Fatalf("cannot start: %s", "port in use")
+
+	if len(codes) != 1 || codes[0] != 1 {
+		t.Errorf("exit function called with %v, want [1]", codes)
+	}
+}
+
+type levelConfig struct {
+	Level Level `json:"level" yaml:"level"`
+}
+
+func TestLevelJSON(t *testing.T) {
+	for _, level := range []Level{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal, LevelPanic} {
+		b, err := json.Marshal(levelConfig{Level: level})
+		if err != nil {
+			t.Fatalf("Marshal(%q) failed: %v", level, err)
+		}
+		var got levelConfig
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("Unmarshal(%s) failed: %v", b, err)
+		}
+		if got.Level != level {
+			t.Errorf("round trip of %q returned %q", level, got.Level)
+		}
+	}
+
+	var alias levelConfig
+	if err := json.Unmarshal([]byte(`{"level":"warning"}`), &alias); err != nil || alias.Level != LevelWarn {
+		t.Errorf("Unmarshal of alias returned %q, %v, want %q", alias.Level, err, LevelWarn)
+	}
+
+	var bad levelConfig
+	err := json.Unmarshal([]byte(`{"level":"loud"}`), &bad)
+	if err == nil || !strings.Contains(err.Error(), "loud") {
+		t.Errorf("Unmarshal of unknown level returned %v, want an error naming the value", err)
+	}
+}
+
+func TestLevelYAML(t *testing.T) {
+	for _, level := range []Level{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal, LevelPanic} {
+		b, err := yaml.Marshal(levelConfig{Level: level})
+		if err != nil {
+			t.Fatalf("Marshal(%q) failed: %v", level, err)
+		}
+		var got levelConfig
+		if err := yaml.Unmarshal(b, &got); err != nil {
+			t.Fatalf("Unmarshal(%s) failed: %v", b, err)
+		}
+		if got.Level != level {
+			t.Errorf("round trip of %q returned %q", level, got.Level)
+		}
+	}
+
+	var bad levelConfig
+	err := yaml.Unmarshal([]byte("level: loud\n"), &bad)
+	if err == nil || !strings.Contains(err.Error(), "loud") {
+		t.Errorf("Unmarshal of unknown level returned %v, want an error naming the value", err)
+	}
+}
+
+// panickingMarshaler is a field value whose MarshalJSON method panics
+type panickingMarshaler struct{}
+
+func (panickingMarshaler) MarshalJSON() ([]byte, error) {
+	panic("MarshalJSON exploded")
+}
+
+func TestPanicWhileFormattingIsRecovered(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(WithOutput(&buf), WithFormat(FormatJSON))
+
+	func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				t.Fatalf("logging panicked: %v", rec)
+			}
+		}()
+		l.WithFields(Fields{"user": panickingMarshaler{}}).Errorf("request by %s failed", "alice")
+	}()
+	l.Info("still logging")
+
+	out := buf.String()
+	if !strings.Contains(out, "failed to log entry") {
+		t.Errorf("the dropped entry wasn't reported: %q", out)
+	}
+	if !strings.Contains(out, "still logging") {
+		t.Errorf("logging stopped working after the panic: %q", out)
+	}
+}
+
+func TestConcurrentWithFields(t *testing.T) {
+	var buf safeBuffer
+	l := NewWithOptions(WithOutput(&buf), WithFormat(FormatText), WithRedaction())
+	shared := l.WithPrefix("api").WithFields(Fields{"service": "users", "msg": "reserved"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				derived := shared.WithFields(Fields{"worker": i, "iteration": j})
+				derived.WithGroup("req").WithFields(Fields{"password": "secret"}).Info("handled")
+				shared.Warnf("worker %d", i)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := strings.Count(buf.String(), "\n"); got != 1600 {
+		t.Errorf("logged %d lines, want 1600", got)
+	}
+}
+
+// safeBuffer bytes.Buffer which can be written concurrently
+type safeBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *safeBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func BenchmarkDerive(b *testing.B) {
+	l := NewWithOptions(WithOutput(ioutil.Discard)).WithFields(Fields{"service": "users", "version": "1.2.3"})
+	fields := Fields{"user": "alice"}
+
+	b.Run("WithFields", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.WithFields(fields)
+		}
+	})
+	b.Run("WithPrefix", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.WithPrefix("db")
+		}
+	})
+	b.Run("WithContext", func(b *testing.B) {
+		ctx := context.Background()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.WithContext(ctx)
+		}
+	})
+	b.Run("Chained", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.WithPrefix("db").WithFields(fields).With(Int("rows", 3))
+		}
+	})
+}
+
+// levelHook sets a level field on every entry, as a careless hook might
+type levelHook struct{}
+
+func (levelHook) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (levelHook) Fire(entry *logrus.Entry) error {
+	data := make(logrus.Fields, len(entry.Data)+1)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	data["level"] = "from hook"
+	entry.Data = data
+	return nil
+}
+
+func TestReservedKeysRenamed(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(WithOutput(&buf), WithFormat(FormatLogfmt), WithHooks(levelHook{}))
+	ctx := NewContext(context.Background(), l)
+	AddField(ctx, "msg", "from bag")
+
+	FromContext(ctx).WithFields(Fields{"time": "from fields"}).Info("hello")
+
+	out := buf.String()
+	for _, want := range []string{
+		"level=info", "msg=hello",
+		`fields.level="from hook"`, `fields.msg="from bag"`, `fields.time="from fields"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %s: %s", want, out)
+		}
+	}
+	if strings.Count(out, " level=") != 1 || strings.Count(out, " msg=") != 1 {
+		t.Errorf("a field overwrote the metadata of the entry: %s", out)
+	}
+}
+
+func TestWithPrefixStacks(t *testing.T) {
+	l, logs := NewObserver()
+	db := l.WithPrefix("db")
+
+	db.WithPrefix("query").WithFields(Fields{"table": "users"}).Info("select")
+	db.Info("connected")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("logged %d entries, want 2", len(entries))
+	}
+	if got := entries[0].Fields["prefix"]; got != "db.query" {
+		t.Errorf("prefix = %v, want db.query", got)
+	}
+	if got := entries[1].Fields["prefix"]; got != "db" {
+		t.Errorf("prefix of the parent = %v, want it unchanged", got)
+	}
+}
+
+func TestWithError(t *testing.T) {
+	l, logs := NewObserver()
+
+	if got := l.WithError(nil); got != l {
+		t.Error("WithError(nil) returned a new logger")
+	}
+	l.WithError(nil).Info("no error")
+	cause := errors.New("connection refused")
+	l.WithError(fmt.Errorf("query users: %w", cause)).Error("failed")
+
+	entries := logs.All()
+	if _, ok := entries[0].Fields[ErrorKey]; ok {
+		t.Errorf("WithError(nil) added an error field: %v", entries[0].Fields)
+	}
+	if got := entries[1].Fields[ErrorKey]; got != "query users: connection refused" {
+		t.Errorf("error field = %v", got)
+	}
+	if got := entries[1].Fields[ErrorCauseKey]; got != "connection refused" {
+		t.Errorf("cause field = %v, want the root cause", got)
+	}
+}
+
+// codedError error carrying a machine readable code
+type codedError struct {
+	code string
+}
+
+func (e codedError) Error() string { return "coded error" }
+func (e codedError) Code() string  { return e.code }
+
+func TestWithCode(t *testing.T) {
+	l, logs := NewObserver()
+
+	l.WithCode("E_QUOTA").Error("explicit")
+	l.WithError(fmt.Errorf("create user: %w", codedError{code: "E_DUPLICATE"})).Error("extracted")
+	l.WithError(codedError{}).Error("empty code")
+
+	entries := logs.All()
+	if got := entries[0].Fields[ErrorCodeKey]; got != "E_QUOTA" {
+		t.Errorf("explicit code = %v, want E_QUOTA", got)
+	}
+	if got := entries[1].Fields[ErrorCodeKey]; got != "E_DUPLICATE" {
+		t.Errorf("extracted code = %v, want E_DUPLICATE", got)
+	}
+	if got, ok := entries[2].Fields[ErrorCodeKey]; ok {
+		t.Errorf("empty code logged as %v", got)
+	}
+}
@@ -0,0 +1,70 @@
+package log
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Multiline decides how the text format renders messages and field values
+// containing newlines
+type Multiline int
+
+const (
+	// MultilineRaw writes newlines as they are
+	MultilineRaw Multiline = iota
+	// MultilineEscape escapes newlines as \n, so every entry is a single line
+	MultilineEscape
+	// MultilineIndent indents continuation lines, so they stand out from the
+	// following entries
+	MultilineIndent
+)
+
+// multilineIndent is prepended to continuation lines by MultilineIndent
+const multilineIndent = "    "
+
+// renderMultiline returns the entry with newlines in the message and string
+// field values rendered according to the mode. The entry is returned as is if
+// there is nothing to render.
+func renderMultiline(entry *logrus.Entry, mode Multiline) *logrus.Entry {
+	if mode == MultilineRaw {
+		return entry
+	}
+
+	var data logrus.Fields
+	for k, v := range entry.Data {
+		text, ok := v.(string)
+		if err, isErr := v.(error); isErr {
+			text, ok = err.Error(), true
+		}
+		if !ok || !strings.ContainsAny(text, "\r\n") {
+			continue
+		}
+		if data == nil {
+			data = make(logrus.Fields, len(entry.Data))
+			for key, value := range entry.Data {
+				data[key] = value
+			}
+		}
+		data[k] = renderLines(text, mode)
+	}
+	if data == nil && !strings.ContainsAny(entry.Message, "\r\n") {
+		return entry
+	}
+
+	rendered := *entry
+	rendered.Message = renderLines(entry.Message, mode)
+	if data != nil {
+		rendered.Data = data
+	}
+	return &rendered
+}
+
+// renderLines renders the newlines of the text according to the mode
+func renderLines(text string, mode Multiline) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if mode == MultilineEscape {
+		return strings.ReplaceAll(strings.ReplaceAll(text, "\r", `\r`), "\n", `\n`)
+	}
+	return strings.ReplaceAll(strings.TrimRight(text, "\n"), "\n", "\n"+multilineIndent)
+}
@@ -0,0 +1,33 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestMultiline(t *testing.T) {
+	clock := fixedClock(time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC))
+	tests := []struct {
+		mode Multiline
+		want string
+	}{
+		{MultilineEscape, `[2024-03-01 12:30:45.000000]  INFO query:\nselect *\nfrom users\n                query=select 1\nfrom dual` + "\n"},
+		{MultilineIndent, "[2024-03-01 12:30:45.000000]  INFO query:\n    select *\n    from users            query=select 1\n    from dual\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		l := NewWithOptions(
+			WithOutput(&buf),
+			WithColors(false),
+			WithClock(clock),
+			WithMultiline(tt.mode),
+		)
+
+		l.WithFields(Fields{"query": "select 1\r\nfrom dual"}).Info("query:\nselect *\nfrom users\n")
+
+		if got := buf.String(); got != tt.want {
+			t.Errorf("mode %d:\ngot  %q\nwant %q", tt.mode, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package log
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// noopLogger discards every entry, it is meant for tests and libraries which
+// need a Logger but don't want any output
+type noopLogger struct{}
+
+// NewNoop returns a logger which discards everything logged on it. Fatal
+// entries do not exit the process and Panic does not panic.
+func NewNoop() Logger {
+	return noopLogger{}
+}
+
+func (noopLogger) Debug(...interface{})          {}
+func (noopLogger) Debugln(...interface{})        {}
+func (noopLogger) Debugf(string, ...interface{}) {}
+func (noopLogger) Infof(string, ...interface{})  {}
+func (noopLogger) Info(string)                   {}
+func (noopLogger) Infoln(...interface{})         {}
+func (noopLogger) Warn(string)                   {}
+func (noopLogger) Warnln(...interface{})         {}
+func (noopLogger) Warnf(string, ...interface{})  {}
+func (noopLogger) Error(string)                  {}
+func (noopLogger) Errorf(string, ...interface{}) {}
+func (noopLogger) Errorln(...interface{})        {}
+func (noopLogger) Fatal(string)                  {}
+func (noopLogger) Fatalf(string, ...interface{}) {}
+func (noopLogger) Fatalln(...interface{})        {}
+func (noopLogger) Panic(string)                  {}
+func (noopLogger) Print(...interface{})          {}
+func (noopLogger) Printf(string, ...interface{}) {}
+func (noopLogger) Println(...interface{})        {}
+func (noopLogger) Trace(...interface{})          {}
+func (noopLogger) Tracef(string, ...interface{}) {}
+func (noopLogger) Traceln(...interface{})        {}
+func (noopLogger) Verbose() bool                 { return false }
+
+func (l noopLogger) WithFields(map[string]interface{}) Logger { return l }
+func (l noopLogger) WithPrefix(string) Logger                 { return l }
+func (l noopLogger) With(...Field) Logger                     { return l }
+func (l noopLogger) WithStruct(interface{}) Logger            { return l }
+func (l noopLogger) WithGroup(string) Logger                  { return l }
+func (l noopLogger) WithContext(context.Context) Logger       { return l }
+func (l noopLogger) WithError(error) Logger                   { return l }
+func (l noopLogger) WithCode(string) Logger                   { return l }
+
+func (noopLogger) Level() Level                 { return LevelInfo }
+func (noopLogger) SetLevel(Level)               {}
+func (noopLogger) SetPrefixLevel(string, Level) {}
+func (noopLogger) RemovePrefixLevel(string)     {}
+func (noopLogger) Enabled(Level) bool           { return false }
+func (noopLogger) AddHook(logrus.Hook)          {}
+func (noopLogger) Close() error                 { return nil }
+
+func (l noopLogger) StartTimer(operation string) *Timer { return newTimer(l, nil, operation) }
@@ -0,0 +1,40 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNoop(t *testing.T) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			t.Fatalf("no-op logger panicked: %v", rec)
+		}
+	}()
+
+	l := NewNoop().
+		WithFields(Fields{"user": "alice"}).
+		WithPrefix("api").
+		WithGroup("req").
+		WithContext(context.Background()).
+		WithError(errors.New("failed")).
+		WithCode("E42").
+		With(String("id", "1"))
+
+	l.Infof("login %s", "succeeded")
+	l.Errorln("failed")
+	l.Fatal("exits nothing")
+	l.Panic("panics nothing")
+	l.StartTimer("query").Stop()
+
+	if l.Verbose() || l.Enabled(LevelPanic) {
+		t.Error("no-op logger reports that it logs")
+	}
+	if got := l.Level(); got != LevelInfo {
+		t.Errorf("Level() = %q, want %q", got, LevelInfo)
+	}
+	if err := l.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+}
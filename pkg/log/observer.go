@@ -0,0 +1,128 @@
+package log
+
+import (
+	"io/ioutil"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Entry is a log entry captured by an observer logger
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Level   Level     `json:"level"`
+	Message string    `json:"msg"`
+	Fields  Fields    `json:"fields,omitempty"`
+}
+
+// newEntry returns a copy of the logrus entry
+func newEntry(entry *logrus.Entry) Entry {
+	fields := make(Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		fields[k] = v
+	}
+
+	return Entry{
+		Time:    entry.Time,
+		Level:   fromLogrusLevel(entry.Level),
+		Message: entry.Message,
+		Fields:  fields,
+	}
+}
+
+// ObservedLogs holds the entries captured by an observer logger
+type ObservedLogs struct {
+	mu      sync.RWMutex
+	entries []Entry
+}
+
+// NewObserver returns a logger which discards its output and captures every
+// entry instead, so tests can make assertions on what was logged
+func NewObserver() (Logger, *ObservedLogs) {
+	logs := &ObservedLogs{}
+	logger := NewWithOptions(
+		WithOutput(ioutil.Discard),
+		WithLevel(LevelTrace),
+		WithHooks(&observerHook{logs: logs}),
+	)
+	return logger, logs
+}
+
+// All returns a copy of all captured entries
+func (o *ObservedLogs) All() []Entry {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	entries := make([]Entry, len(o.entries))
+	copy(entries, o.entries)
+	return entries
+}
+
+// Len returns the number of captured entries
+func (o *ObservedLogs) Len() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	return len(o.entries)
+}
+
+// FilterLevel returns the captured entries logged at the given level
+func (o *ObservedLogs) FilterLevel(level Level) []Entry {
+	return o.filter(func(e Entry) bool {
+		return e.Level == level
+	})
+}
+
+// FilterField returns the captured entries which carry the given field value
+func (o *ObservedLogs) FilterField(key string, value interface{}) []Entry {
+	return o.filter(func(e Entry) bool {
+		v, ok := e.Fields[key]
+		return ok && reflect.DeepEqual(v, value)
+	})
+}
+
+// Reset removes all captured entries
+func (o *ObservedLogs) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.entries = nil
+}
+
+func (o *ObservedLogs) filter(keep func(e Entry) bool) []Entry {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	var entries []Entry
+	for _, e := range o.entries {
+		if keep(e) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+func (o *ObservedLogs) add(e Entry) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.entries = append(o.entries, e)
+}
+
+// observerHook hook for logrus to capture entries into ObservedLogs
+type observerHook struct {
+	logs *ObservedLogs
+}
+
+// Fire func used by logrus to capture the entry
+func (hook *observerHook) Fire(entry *logrus.Entry) error {
+	hook.logs.add(newEntry(entry))
+	return nil
+}
+
+// Levels defines in which log levels the observer hook works
+func (hook *observerHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
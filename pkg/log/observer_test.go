@@ -0,0 +1,67 @@
+package log
+
+import "testing"
+
+func TestObserverCapturesFields(t *testing.T) {
+	l, logs := NewObserver()
+
+	l.WithPrefix("db").WithFields(Fields{"table": "users"}).Errorf("query failed after %d tries", 3)
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Level != LevelError {
+		t.Errorf("Level = %q, want %q", e.Level, LevelError)
+	}
+	if e.Message != "query failed after 3 tries" {
+		t.Errorf("Message = %q, want the formatted message", e.Message)
+	}
+	if e.Fields["table"] != "users" {
+		t.Errorf("Fields[table] = %v, want users", e.Fields["table"])
+	}
+	if e.Fields["prefix"] != "db" {
+		t.Errorf("Fields[prefix] = %v, want db", e.Fields["prefix"])
+	}
+}
+
+func TestObserverFilterLevel(t *testing.T) {
+	l, logs := NewObserver()
+
+	l.Debug("one")
+	l.Info("two")
+	l.Info("three")
+	l.Warn("four")
+
+	if got := len(logs.FilterLevel(LevelInfo)); got != 2 {
+		t.Errorf("FilterLevel(info) returned %d entries, want 2", got)
+	}
+	if got := len(logs.FilterLevel(LevelError)); got != 0 {
+		t.Errorf("FilterLevel(error) returned %d entries, want 0", got)
+	}
+	if got := logs.Len(); got != 4 {
+		t.Errorf("Len() = %d, want 4", got)
+	}
+}
+
+func TestObserverFilterField(t *testing.T) {
+	l, logs := NewObserver()
+
+	l.WithFields(Fields{"user": "alice"}).Info("login")
+	l.WithFields(Fields{"user": "bob"}).Info("login")
+	l.WithFields(Fields{"user": "alice"}).Info("logout")
+
+	entries := logs.FilterField("user", "alice")
+	if len(entries) != 2 {
+		t.Fatalf("FilterField returned %d entries, want 2", len(entries))
+	}
+	if entries[0].Message != "login" || entries[1].Message != "logout" {
+		t.Errorf("FilterField returned %q and %q, want login and logout", entries[0].Message, entries[1].Message)
+	}
+
+	logs.Reset()
+	if got := logs.Len(); got != 0 {
+		t.Errorf("Len() after Reset = %d, want 0", got)
+	}
+}
@@ -0,0 +1,36 @@
+package log
+
+import (
+	"sync"
+)
+
+// onceSeen holds the keys LogOnce already logged
+var onceSeen sync.Map
+
+// LogOnce logs the message at the level only the first time it is called with
+// the key in this process, e.g. for deprecation warnings emitted on every config
+// reload. An empty key uses the message as key. Calls while the level is
+// disabled don't count.
+//
+//	log.LogOnce(logger, log.LevelWarn, "config.port", "port is deprecated, use server.port")
+func LogOnce(l Logger, level Level, key, msg string) {
+	if !l.Enabled(level) {
+		return
+	}
+	if key == "" {
+		key = msg
+	}
+	if _, seen := onceSeen.LoadOrStore(key, struct{}{}); seen {
+		return
+	}
+	logAt(l, level, msg)
+}
+
+// ResetOnce forgets the keys logged by LogOnce so they are logged again, it is
+// meant for tests
+func ResetOnce() {
+	onceSeen.Range(func(key, _ interface{}) bool {
+		onceSeen.Delete(key)
+		return true
+	})
+}
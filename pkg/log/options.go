@@ -0,0 +1,365 @@
+package log
+
+import (
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/sirupsen/logrus"
+)
+
+// config holds the settings a logger is built from
+type config struct {
+	output     io.Writer
+	outLevel   Level
+	level      Level
+	file       string
+	syslog     *syslogConfig
+	rotation   FileRotation
+	outputs    []Output
+	errOutput  io.Writer
+	format     Format
+	timeFormat string
+	levelKey   string
+	flatten    int
+	multiline  Multiline
+	humanize   bool
+	fieldWidth int
+	csvColumns []string
+	csvPack    bool
+	formatter  logrus.Formatter
+	colors     *bool
+	theme      *ColorTheme
+	service    string
+	env        string
+	version    string
+	host       string
+	ctxFields  []ContextField
+	ctxExtract []ContextExtractor
+	hooks      []logrus.Hook
+	utc        bool
+	clock      Clock
+	caller     bool
+	callerFn   bool
+	stack      bool
+	redact     []string
+	scrub      []*regexp.Regexp
+	exitFunc   func(int)
+}
+
+// Option configures a logger created by NewWithOptions
+type Option func(*config)
+
+// defaultConfig returns the settings used when no option overrides them
+func defaultConfig() *config {
+	return &config{
+		output:     os.Stderr,
+		level:      LevelInfo,
+		format:     FormatText,
+		timeFormat: timestampFormat,
+		ctxFields:  DefaultContextFields,
+	}
+}
+
+// WithOutput sets the writer the logger writes to
+func WithOutput(wr io.Writer) Option {
+	return func(c *config) {
+		if wr != nil {
+			c.output = wr
+		}
+	}
+}
+
+// WithLevel sets the minimum level of entries that get logged
+func WithLevel(level Level) Option {
+	return func(c *config) {
+		c.level = level
+	}
+}
+
+// WithFile additionally writes every entry to the given file
+func WithFile(file string) Option {
+	return func(c *config) {
+		c.file = file
+	}
+}
+
+// syslogConfig holds the connection settings of the syslog hook
+type syslogConfig struct {
+	network string
+	addr    string
+	tag     string
+}
+
+// WithSyslog additionally forwards every entry to syslog, see NewSyslogHook
+func WithSyslog(network, addr, tag string) Option {
+	return func(c *config) {
+		c.syslog = &syslogConfig{network: network, addr: addr, tag: tag}
+	}
+}
+
+// WithOutputs writes entries to the given outputs in addition to the primary
+// output, each with its own format and minimum level, e.g. text on the console
+// and JSON for a collector:
+//
+//	log.NewWithOptions(
+//		log.WithOutput(os.Stderr),
+//		log.WithOutputs(log.Output{Writer: conn, Format: log.FormatJSON}),
+//	)
+func WithOutputs(outputs ...Output) Option {
+	return func(c *config) {
+		c.outputs = append(c.outputs, outputs...)
+	}
+}
+
+// WithOutputLevel sets the minimum level written to the writer set by
+// WithOutput, independent of the other outputs. The level of the logger stays
+// the floor, e.g. debug entries go to a file but only info and above to the
+// console with:
+//
+//	log.NewWithOptions(
+//		log.WithLevel(log.LevelDebug),
+//		log.WithOutputLevel(log.LevelInfo),
+//		log.WithOutputs(log.Output{Writer: file, Level: log.LevelDebug}),
+//	)
+func WithOutputLevel(level Level) Option {
+	return func(c *config) {
+		c.outLevel = level
+	}
+}
+
+// WithErrorOutput additionally writes entries at LevelError and above, including
+// fatal and panic entries, to the given writer without colors
+func WithErrorOutput(wr io.Writer) Option {
+	return func(c *config) {
+		c.errOutput = wr
+	}
+}
+
+// WithServiceName sets the service name added by formatters which support it
+func WithServiceName(name string) Option {
+	return func(c *config) {
+		c.service = name
+	}
+}
+
+// WithEnvironment sets the deployment environment added by formatters which
+// support it, e.g. "production"
+func WithEnvironment(env string) Option {
+	return func(c *config) {
+		c.env = env
+	}
+}
+
+// WithVersion sets the version of the service added by formatters which
+// support it
+func WithVersion(version string) Option {
+	return func(c *config) {
+		c.version = version
+	}
+}
+
+// WithHost sets the host added by formatters which support it, it defaults to
+// the hostname of the machine
+func WithHost(host string) Option {
+	return func(c *config) {
+		c.host = host
+	}
+}
+
+// WithContextFields sets the context values WithContext adds as fields
+func WithContextFields(fields ...ContextField) Option {
+	return func(c *config) {
+		c.ctxFields = fields
+	}
+}
+
+// WithContextExtractors adds extractors whose fields WithContext adds as well
+func WithContextExtractors(extractors ...ContextExtractor) Option {
+	return func(c *config) {
+		c.ctxExtract = append(c.ctxExtract, extractors...)
+	}
+}
+
+// WithHooks registers additional logrus hooks on the logger
+func WithHooks(hooks ...logrus.Hook) Option {
+	return func(c *config) {
+		c.hooks = append(c.hooks, hooks...)
+	}
+}
+
+// WithUTC renders the timestamps of every entry in UTC instead of the local
+// timezone of the host
+func WithUTC() Option {
+	return func(c *config) {
+		c.utc = true
+	}
+}
+
+// WithClock sets the clock entries take their time from, e.g. a fixed clock
+// to get deterministic output in tests
+func WithClock(clock Clock) Option {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
+
+// WithCaller adds the file and line of the call site to every entry, and the
+// function name if withFunction is set
+func WithCaller(withFunction bool) Option {
+	return func(c *config) {
+		c.caller = true
+		c.callerFn = withFunction
+	}
+}
+
+// WithRedaction masks the values of the given field keys, matched
+// case-insensitively, in every output. DefaultRedactedKeys are used if no keys
+// are given.
+func WithRedaction(keys ...string) Option {
+	return func(c *config) {
+		if len(keys) == 0 {
+			keys = DefaultRedactedKeys
+		}
+		c.redact = keys
+	}
+}
+
+// WithScrubbing masks every match of the given patterns in the message of an
+// entry, e.g. EmailPattern or CardNumberPattern. Patterns are applied in the
+// given order on every entry, so they should be compiled once up front.
+func WithScrubbing(patterns ...*regexp.Regexp) Option {
+	return func(c *config) {
+		c.scrub = append(c.scrub, patterns...)
+	}
+}
+
+// WithExitFunc replaces os.Exit which is called after fatal entries. It is
+// meant for tests only, to cover fatal code paths without ending the process.
+func WithExitFunc(exit func(code int)) Option {
+	return func(c *config) {
+		c.exitFunc = exit
+	}
+}
+
+// WithStackTrace attaches the stack trace of the call site to error, fatal and
+// panic entries. Capturing the stack is expensive, so it is disabled by default.
+func WithStackTrace() Option {
+	return func(c *config) {
+		c.stack = true
+	}
+}
+
+// WithFileRotation rotates the file set by WithFile once it exceeds the max
+// size, zero values fall back to DefaultRotationMaxSize and
+// DefaultRotationMaxBackups
+func WithFileRotation(rotation FileRotation) Option {
+	return func(c *config) {
+		if rotation.MaxSize <= 0 {
+			rotation.MaxSize = DefaultRotationMaxSize
+		}
+		if rotation.MaxBackups <= 0 {
+			rotation.MaxBackups = DefaultRotationMaxBackups
+		}
+		c.rotation = rotation
+	}
+}
+
+// WithFormat sets the format entries are rendered in
+func WithFormat(format Format) Option {
+	return func(c *config) {
+		c.format = format
+	}
+}
+
+// WithTimestampFormat sets the layout of timestamps in the text, JSON and logfmt
+// formats, an empty layout keeps the default. ECS and GELF use the timestamp
+// format their specification requires.
+func WithTimestampFormat(layout string) Option {
+	return func(c *config) {
+		if layout != "" {
+			c.timeFormat = layout
+		}
+	}
+}
+
+// WithLevelKey sets the key the level is logged under in the JSON and
+// CloudWatch formats, e.g. "severity" instead of "level"
+func WithLevelKey(key string) Option {
+	return func(c *config) {
+		c.levelKey = key
+	}
+}
+
+// WithFlattening expands nested maps and structs logged as field values into
+// dotted keys, e.g. "user.id", in the text and logfmt formats, up to the given
+// depth. JSON output keeps them nested, GELF output is always flattened. A
+// depth of zero or less uses DefaultFlattenDepth.
+func WithFlattening(maxDepth int) Option {
+	return func(c *config) {
+		if maxDepth <= 0 {
+			maxDepth = DefaultFlattenDepth
+		}
+		c.flatten = maxDepth
+	}
+}
+
+// WithMultiline sets how the text format renders messages and field values
+// containing newlines, like stack traces or SQL queries. The logfmt and JSON
+// formats always escape them.
+func WithMultiline(mode Multiline) Option {
+	return func(c *config) {
+		c.multiline = mode
+	}
+}
+
+// WithFieldColumns aligns the fields of the text format in columns of the
+// given width, sorted by key, so they are easier to scan in a terminal
+func WithFieldColumns(width int) Option {
+	return func(c *config) {
+		c.fieldWidth = width
+	}
+}
+
+// WithCSVColumns sets the fields the CSV and TSV formats write into columns
+// after time, level and msg, in the given order. Other fields are dropped
+// unless packFields is set, which writes them as JSON object into a trailing
+// fields column. CSVHeader returns the matching header row.
+func WithCSVColumns(packFields bool, columns ...string) Option {
+	return func(c *config) {
+		c.csvColumns = columns
+		c.csvPack = packFields
+	}
+}
+
+// WithHumanizedValues renders time.Duration and ByteSize field values readable
+// in the text and logfmt formats, e.g. 1.2s and 3.4MB. JSON output keeps the
+// raw numbers.
+func WithHumanizedValues() Option {
+	return func(c *config) {
+		c.humanize = true
+	}
+}
+
+// WithFormatter sets a custom formatter, it takes precedence over WithFormat
+func WithFormatter(formatter logrus.Formatter) Option {
+	return func(c *config) {
+		c.formatter = formatter
+	}
+}
+
+// WithColorTheme sets the colors of the text format
+func WithColorTheme(theme ColorTheme) Option {
+	return func(c *config) {
+		c.theme = &theme
+	}
+}
+
+// WithColors forces colored text output on or off. Without it colors are
+// used if the output is a terminal and the NO_COLOR environment variable is
+// not set.
+func WithColors(enabled bool) Option {
+	return func(c *config) {
+		c.colors = &enabled
+	}
+}
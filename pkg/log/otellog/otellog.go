@@ -0,0 +1,82 @@
+// Package otellog correlates log entries with OpenTelemetry traces. It is kept
+// apart from the log package so only users of OpenTelemetry depend on it.
+package otellog
+
+import (
+	"context"
+	"encoding/binary"
+	"strconv"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TraceIDKey field the trace id is logged under
+	TraceIDKey = "trace_id"
+	// SpanIDKey field the span id is logged under
+	SpanIDKey = "span_id"
+)
+
+// WithTraceCorrelation makes WithContext add the trace and span id of the
+// active span in the context
+func WithTraceCorrelation() log.Option {
+	return log.WithContextExtractors(TraceFields)
+}
+
+// TraceFields returns the trace and span id of the active span in ctx, or no
+// fields if there is no valid span
+func TraceFields(ctx context.Context) log.Fields {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return nil
+	}
+	return log.Fields{
+		TraceIDKey: spanCtx.TraceID().String(),
+		SpanIDKey:  spanCtx.SpanID().String(),
+	}
+}
+
+const (
+	// DatadogTraceIDKey field the trace id is logged under for Datadog
+	DatadogTraceIDKey = "dd.trace_id"
+	// DatadogSpanIDKey field the span id is logged under for Datadog
+	DatadogSpanIDKey = "dd.span_id"
+)
+
+// WithDatadogCorrelation makes WithContext add the trace and span id of the
+// active span in the context in the form Datadog correlates them with traces,
+// to be used with log.FormatDatadog
+func WithDatadogCorrelation() log.Option {
+	return log.WithContextExtractors(DatadogTraceFields)
+}
+
+// DatadogTraceFields returns the trace and span id of the active span in ctx as
+// decimal numbers, the trace id reduced to its lower 64 bits as Datadog
+// expects, or no fields if there is no valid span
+func DatadogTraceFields(ctx context.Context) log.Fields {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return nil
+	}
+	traceID := spanCtx.TraceID()
+	spanID := spanCtx.SpanID()
+	return log.Fields{
+		DatadogTraceIDKey: strconv.FormatUint(binary.BigEndian.Uint64(traceID[8:]), 10),
+		DatadogSpanIDKey:  strconv.FormatUint(binary.BigEndian.Uint64(spanID[:]), 10),
+	}
+}
+
+// IsSampled reports whether the active span in ctx is sampled by the tracer
+func IsSampled(ctx context.Context) bool {
+	return trace.SpanContextFromContext(ctx).IsSampled()
+}
+
+// NewTraceSampled returns a logger which logs all entries of traces sampled by
+// the tracer and of the given rate of the other traces, deciding by trace id
+// so the entries of a trace are kept or dropped together. The logger must be
+// configured with WithTraceCorrelation and bound to the request context
+// through WithContext.
+func NewTraceSampled(l log.Logger, rate float64) log.Logger {
+	return log.NewTraceSampled(l, rate, IsSampled)
+}
@@ -0,0 +1,69 @@
+package otellog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var spanCtx = trace.NewSpanContext(trace.SpanContextConfig{
+	TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+	SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	TraceFlags: trace.FlagsSampled,
+})
+
+// logEntry logs an entry with a logger bound to ctx and returns its fields
+func logEntry(t *testing.T, ctx context.Context, opt log.Option) map[string]interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	l := log.NewWithOptions(log.WithOutput(&buf), log.WithFormat(log.FormatJSON), opt)
+	l.WithContext(ctx).Info("handled")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", buf.String(), err)
+	}
+	return entry
+}
+
+func TestTraceCorrelation(t *testing.T) {
+	ctx := trace.ContextWithSpanContext(context.Background(), spanCtx)
+
+	entry := logEntry(t, ctx, WithTraceCorrelation())
+	if entry[TraceIDKey] != "4bf92f3577b34da6a3ce929d0e0e4736" || entry[SpanIDKey] != "00f067aa0ba902b7" {
+		t.Errorf("trace fields = %v %v, want the ids of the span", entry[TraceIDKey], entry[SpanIDKey])
+	}
+	if !IsSampled(ctx) {
+		t.Error("IsSampled = false for a sampled span")
+	}
+}
+
+func TestTraceCorrelationWithoutSpan(t *testing.T) {
+	entry := logEntry(t, context.Background(), WithTraceCorrelation())
+	if _, ok := entry[TraceIDKey]; ok {
+		t.Errorf("trace id logged without a span: %v", entry)
+	}
+	if _, ok := entry[SpanIDKey]; ok {
+		t.Errorf("span id logged without a span: %v", entry)
+	}
+	if IsSampled(context.Background()) {
+		t.Error("IsSampled = true without a span")
+	}
+}
+
+func TestDatadogCorrelation(t *testing.T) {
+	ctx := trace.ContextWithSpanContext(context.Background(), spanCtx)
+
+	fields := DatadogTraceFields(ctx)
+	// lower 64 bits of the trace id and the span id in decimal
+	if fields[DatadogTraceIDKey] != "11803532876627986230" || fields[DatadogSpanIDKey] != "67667974448284343" {
+		t.Errorf("Datadog fields = %v", fields)
+	}
+	if fields := DatadogTraceFields(context.Background()); fields != nil {
+		t.Errorf("Datadog fields without a span = %v, want none", fields)
+	}
+}
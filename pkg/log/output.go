@@ -0,0 +1,101 @@
+package log
+
+import (
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Output is an additional destination entries are written to
+type Output struct {
+	// Writer receives the rendered entries.
+	Writer io.Writer
+	// Format renders entries for this output in another format than the
+	// logger, e.g. JSON for a collector next to text on the console. The
+	// format of the logger is used when empty.
+	Format Format
+	// Formatter renders entries for this output, it takes precedence over
+	// Format.
+	Formatter logrus.Formatter
+	// Level is the minimum level written to this output, every level the
+	// logger emits is written when empty.
+	Level Level
+}
+
+// writerHook hook for logrus to write entries to an additional output
+type writerHook struct {
+	writer    io.Writer
+	formatter logrus.Formatter
+	levels    []logrus.Level
+}
+
+// newWriterHook returns a hook writing to the given output with the formatter
+func newWriterHook(output Output, formatter logrus.Formatter) *writerHook {
+	return &writerHook{
+		writer:    output.Writer,
+		formatter: formatter,
+		levels:    levelsFrom(output.Level),
+	}
+}
+
+// Fire func used by logrus to write the entry to the output
+func (hook *writerHook) Fire(entry *logrus.Entry) error {
+	line, err := hook.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	_, err = hook.writer.Write(line)
+	return err
+}
+
+// Levels defines in which log levels the writer hook works
+func (hook *writerHook) Levels() []logrus.Level {
+	return hook.levels
+}
+
+// discardFormatter renders nothing, for loggers whose primary output is
+// written by a hook
+type discardFormatter struct{}
+
+// Format func used by logrus to format the log
+func (discardFormatter) Format(*logrus.Entry) ([]byte, error) {
+	return nil, nil
+}
+
+// getOutputFormatter returns the formatter of the output, or one for its
+// format and the settings of the logger. Text is colored only if the writer is
+// a terminal.
+func (c *config) getOutputFormatter(output Output) logrus.Formatter {
+	if output.Formatter != nil {
+		return output.Formatter
+	}
+
+	sink := *c
+	if output.Format != "" {
+		sink.format = output.Format
+		sink.formatter = nil
+	}
+	terminal := isTerminal(output.Writer)
+	if terminal && sink.colors == nil && os.Getenv(EnvNoColor) == "" {
+		colors := true
+		sink.colors = &colors
+	}
+	return sink.getFormatter(!terminal)
+}
+
+// levelsFrom returns all logrus levels at least as severe as the given level
+func levelsFrom(level Level) []logrus.Level {
+	lvl, err := logrus.ParseLevel(level.String())
+	if err != nil {
+		return logrus.AllLevels
+	}
+
+	var levels []logrus.Level
+	for _, l := range logrus.AllLevels {
+		if l <= lvl {
+			levels = append(levels, l)
+		}
+	}
+	return levels
+}
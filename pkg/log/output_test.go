@@ -0,0 +1,99 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestOutputLevels(t *testing.T) {
+	var console, file bytes.Buffer
+	l := NewWithOptions(
+		WithLevel(LevelDebug),
+		WithOutput(&console),
+		WithOutputLevel(LevelInfo),
+		WithOutputs(Output{Writer: &file, Level: LevelDebug}),
+	)
+
+	l.Debug("cache warmed")
+	l.Info("server started")
+
+	if !strings.Contains(file.String(), "cache warmed") {
+		t.Errorf("debug entry didn't reach the file: %q", file.String())
+	}
+	if strings.Contains(console.String(), "cache warmed") {
+		t.Errorf("debug entry reached the info console: %q", console.String())
+	}
+	for name, out := range map[string]string{"console": console.String(), "file": file.String()} {
+		if !strings.Contains(out, "server started") {
+			t.Errorf("info entry didn't reach the %s: %q", name, out)
+		}
+	}
+}
+
+func TestOutputLevelBelowLoggerLevel(t *testing.T) {
+	var console, file bytes.Buffer
+	l := NewWithOptions(
+		WithLevel(LevelInfo),
+		WithOutput(&console),
+		WithOutputs(Output{Writer: &file, Level: LevelDebug}),
+	)
+
+	l.Debug("cache warmed")
+
+	if file.Len() != 0 || console.Len() != 0 {
+		t.Errorf("the logger level is the floor of all outputs, got file %q console %q", file.String(), console.String())
+	}
+}
+
+func TestErrorsAlsoGoToSecondOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  func(w *bytes.Buffer) Option
+	}{
+		{"WithOutputs", func(w *bytes.Buffer) Option { return WithOutputs(Output{Writer: w, Level: LevelError}) }},
+		{"WithErrorOutput", func(w *bytes.Buffer) Option { return WithErrorOutput(w) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var a, b bytes.Buffer
+			l := NewWithOptions(WithOutput(&a), WithLevel(LevelInfo), tt.opt(&b))
+
+			l.Info("server started")
+			l.Error("connection lost")
+
+			if !strings.Contains(a.String(), "server started") || !strings.Contains(a.String(), "connection lost") {
+				t.Errorf("A lacks entries: %q", a.String())
+			}
+			if strings.Contains(b.String(), "server started") {
+				t.Errorf("info entry reached B: %q", b.String())
+			}
+			if !strings.Contains(b.String(), "connection lost") {
+				t.Errorf("error entry didn't reach B: %q", b.String())
+			}
+		})
+	}
+}
+
+func TestOutputsWithDifferentFormats(t *testing.T) {
+	var console, collector bytes.Buffer
+	l := NewWithOptions(
+		WithOutput(&console),
+		WithColors(false),
+		WithOutputs(Output{Writer: &collector, Format: FormatJSON}),
+	)
+
+	l.WithFields(Fields{"user": "alice"}).Info("login")
+
+	if !strings.Contains(console.String(), " INFO login") || !strings.Contains(console.String(), "user=alice") {
+		t.Errorf("console isn't text: %q", console.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(collector.Bytes(), &entry); err != nil {
+		t.Fatalf("collector output isn't JSON: %q: %v", collector.Bytes(), err)
+	}
+	if entry["msg"] != "login" || entry["user"] != "alice" || entry["level"] != "info" {
+		t.Errorf("unexpected collector entry: %v", entry)
+	}
+}
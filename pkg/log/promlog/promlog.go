@@ -0,0 +1,56 @@
+// Package promlog counts emitted log entries with Prometheus. It is kept apart
+// from the log package so only users of Prometheus depend on it.
+package promlog
+
+import (
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Hook hook for logrus which counts the emitted entries in the
+// log_lines_total counter, labeled by level and optionally by prefix
+type Hook struct {
+	counter    *prometheus.CounterVec
+	withPrefix bool
+}
+
+// NewHook registers the log_lines_total counter on the registerer and returns
+// a hook incrementing it, to be passed to log.WithHooks
+func NewHook(registerer prometheus.Registerer, withPrefix bool) (*Hook, error) {
+	labels := []string{"level"}
+	if withPrefix {
+		labels = append(labels, "prefix")
+	}
+
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "log_lines_total",
+		Help: "Number of emitted log entries.",
+	}, labels)
+	if err := registerer.Register(counter); err != nil {
+		return nil, err
+	}
+
+	return &Hook{counter: counter, withPrefix: withPrefix}, nil
+}
+
+// Fire func used by logrus to count the entry
+func (hook *Hook) Fire(entry *logrus.Entry) error {
+	level, err := log.ParseLevel(entry.Level.String())
+	if err != nil {
+		return err
+	}
+
+	labels := prometheus.Labels{"level": string(level)}
+	if hook.withPrefix {
+		prefix, _ := entry.Data["prefix"].(string)
+		labels["prefix"] = prefix
+	}
+	hook.counter.With(labels).Inc()
+	return nil
+}
+
+// Levels defines in which log levels the hook works
+func (hook *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
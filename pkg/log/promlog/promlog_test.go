@@ -0,0 +1,59 @@
+package promlog
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHookCountsByLevel(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	hook, err := NewHook(registry, false)
+	if err != nil {
+		t.Fatalf("NewHook failed: %v", err)
+	}
+	l := log.NewWithOptions(log.WithOutput(ioutil.Discard), log.WithLevel(log.LevelInfo), log.WithHooks(hook))
+
+	for i := 0; i < 5; i++ {
+		l.Error("failed")
+	}
+	l.Info("started")
+	l.Debug("disabled")
+
+	if got := testutil.ToFloat64(hook.counter.WithLabelValues("error")); got != 5 {
+		t.Errorf("error count = %v, want 5", got)
+	}
+	if got := testutil.ToFloat64(hook.counter.WithLabelValues("info")); got != 1 {
+		t.Errorf("info count = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(hook.counter.WithLabelValues("debug")); got != 0 {
+		t.Errorf("debug count = %v, want 0 as debug is disabled", got)
+	}
+}
+
+func TestHookCountsByPrefix(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	hook, err := NewHook(registry, true)
+	if err != nil {
+		t.Fatalf("NewHook failed: %v", err)
+	}
+	l := log.NewWithOptions(log.WithOutput(ioutil.Discard), log.WithHooks(hook))
+
+	l.WithPrefix("db").Warn("slow query")
+	l.WithPrefix("db").Warn("slow query")
+	l.Warn("no prefix")
+
+	if got := testutil.ToFloat64(hook.counter.WithLabelValues("warn", "db")); got != 2 {
+		t.Errorf("db warn count = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(hook.counter.WithLabelValues("warn", "")); got != 1 {
+		t.Errorf("unprefixed warn count = %v, want 1", got)
+	}
+
+	if _, err := NewHook(registry, true); err == nil {
+		t.Error("registering the counter twice succeeded, want an error")
+	}
+}
@@ -0,0 +1,144 @@
+package log
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RedactedValue replaces the value of sensitive fields
+const RedactedValue = "***REDACTED***"
+
+// DefaultRedactedKeys are the sensitive field keys used by WithRedaction when
+// no keys are given
+var DefaultRedactedKeys = []string{"password", "token", "authorization", "secret"}
+
+// maxRedactDepth is the number of nested maps and structs below a field value
+// which are searched for sensitive keys
+const maxRedactDepth = 8
+
+// redactHook hook for logrus which masks the values of sensitive fields before
+// any formatter or other hook sees them
+type redactHook struct {
+	keys map[string]bool
+}
+
+// newRedactHook returns a hook redacting the given keys case-insensitively
+func newRedactHook(keys []string) *redactHook {
+	hook := &redactHook{keys: make(map[string]bool, len(keys))}
+	for _, key := range keys {
+		hook.keys[strings.ToLower(key)] = true
+	}
+	return hook
+}
+
+// Fire func used by logrus to redact the entry, including the fields of groups
+// added by WithGroup and the keys of nested maps and structs. The field maps
+// are shared with the logger the entry was created from, so they are replaced
+// instead of modified.
+func (hook *redactHook) Fire(entry *logrus.Entry) error {
+	if data := hook.redact(entry.Data, maxRedactDepth); data != nil {
+		entry.Data = data
+	}
+	return nil
+}
+
+// redact returns a copy of the fields with the sensitive values masked, or nil
+// if there is nothing to mask
+func (hook *redactHook) redact(fields map[string]interface{}, depth int) map[string]interface{} {
+	var data map[string]interface{}
+	for k, v := range fields {
+		if hook.sensitive(k) {
+			v = RedactedValue
+		} else if redacted, ok := hook.redactValue(v, depth); ok {
+			v = redacted
+		} else {
+			continue
+		}
+
+		if data == nil {
+			data = make(map[string]interface{}, len(fields))
+			for key, value := range fields {
+				data[key] = value
+			}
+		}
+		data[k] = v
+	}
+	return data
+}
+
+// redactValue returns the value with the sensitive keys of a group, map or
+// struct masked and whether anything was masked. Maps and structs with masked
+// keys are replaced by a map of their entries, as flattening would render them.
+func (hook *redactHook) redactValue(v interface{}, depth int) (interface{}, bool) {
+	if group, ok := v.(Fields); ok {
+		if redacted := hook.redact(group, depth); redacted != nil {
+			return Fields(redacted), true
+		}
+		return v, false
+	}
+	if depth <= 0 || !flattenable(v) {
+		return v, false
+	}
+	if redacted := hook.redact(nestedFields(v), depth-1); redacted != nil {
+		return redacted, true
+	}
+	return v, false
+}
+
+// Levels defines in which log levels the redact hook works
+func (hook *redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (hook *redactHook) sensitive(key string) bool {
+	return hook.keys[strings.ToLower(key)]
+}
+
+var (
+	// EmailPattern matches email addresses
+	EmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// CardNumberPattern matches 13 to 19 digit card numbers, optionally
+	// grouped by spaces or dashes
+	CardNumberPattern = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+)
+
+// scrubHook hook for logrus which masks matches of the patterns in the message
+type scrubHook struct {
+	patterns []*regexp.Regexp
+}
+
+// Fire func used by logrus to scrub the message of the entry
+func (hook *scrubHook) Fire(entry *logrus.Entry) error {
+	for _, pattern := range hook.patterns {
+		entry.Message = pattern.ReplaceAllLiteralString(entry.Message, RedactedValue)
+	}
+	return nil
+}
+
+// Levels defines in which log levels the scrub hook works
+func (hook *scrubHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// RedactBody masks the values of the given keys, matched case-insensitively, in
+// a JSON or form encoded body. DefaultRedactedKeys are used if no keys are
+// given. The body may be truncated, so it isn't parsed but matched by patterns.
+func RedactBody(body string, keys ...string) string {
+	if len(keys) == 0 {
+		keys = DefaultRedactedKeys
+	}
+
+	quoted := make([]string, len(keys))
+	for i, key := range keys {
+		quoted[i] = regexp.QuoteMeta(key)
+	}
+	names := strings.Join(quoted, "|")
+
+	jsonPattern := regexp.MustCompile(`(?i)("(?:` + names + `)"\s*:\s*)(?:"(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+	body = jsonPattern.ReplaceAllString(body, `${1}"`+RedactedValue+`"`)
+
+	formPattern := regexp.MustCompile(`(?i)(^|[&?])((?:` + names + `)=)[^&\s]*`)
+	return formPattern.ReplaceAllString(body, `${1}${2}`+RedactedValue)
+}
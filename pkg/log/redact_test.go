@@ -0,0 +1,92 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRedactionHidesPassword(t *testing.T) {
+	for _, format := range []Format{FormatText, FormatJSON, FormatLogfmt} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			l := NewWithOptions(WithOutput(&buf), WithFormat(format), WithRedaction())
+
+			l.WithFields(Fields{"user": "alice", "Password": "hunter2"}).Info("login")
+			l.WithPrefix("auth").WithFields(Fields{"password": "hunter2"}).Info("login")
+
+			out := buf.String()
+			if strings.Contains(out, "hunter2") {
+				t.Errorf("password reached the writer in cleartext: %s", out)
+			}
+			if strings.Count(out, RedactedValue) != 2 {
+				t.Errorf("want 2 redacted values, got: %s", out)
+			}
+			if !strings.Contains(out, "alice") {
+				t.Errorf("non-sensitive field was redacted: %s", out)
+			}
+		})
+	}
+}
+
+func TestRedactionCustomKeys(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(WithOutput(&buf), WithFormat(FormatJSON), WithRedaction("pin"))
+
+	l.WithFields(Fields{"PIN": "1234", "password": "visible"}).Info("login")
+
+	out := buf.String()
+	if strings.Contains(out, "1234") {
+		t.Errorf("pin reached the writer in cleartext: %s", out)
+	}
+	if !strings.Contains(out, "visible") {
+		t.Errorf("only the configured keys should be redacted: %s", out)
+	}
+}
+
+func TestRedactionInGroups(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(WithOutput(&buf), WithFormat(FormatJSON), WithRedaction())
+
+	req := l.WithGroup("request").WithGroup("auth").WithFields(Fields{"token": "abc123", "user": "alice"})
+	req.Info("authenticated")
+	req.Info("authenticated again")
+
+	out := buf.String()
+	if strings.Contains(out, "abc123") {
+		t.Errorf("token in a group reached the writer in cleartext: %s", out)
+	}
+	if strings.Count(out, RedactedValue) != 2 || strings.Count(out, "alice") != 2 {
+		t.Errorf("want the token redacted and the user kept in both entries: %s", out)
+	}
+}
+
+// credentials struct logged as a field value
+type credentials struct {
+	User     string
+	Password string
+}
+
+func TestRedactionInNestedValues(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(WithOutput(&buf), WithFormat(FormatJSON), WithRedaction())
+
+	l.WithFields(Fields{
+		"login": map[string]interface{}{"user": "alice", "password": "hunter2"},
+		"creds": &credentials{User: "bob", Password: "s3cret"},
+		"deep":  map[string]interface{}{"a": map[string]string{"token": "abc123"}},
+	}).Info("login")
+
+	out := buf.String()
+	for _, secret := range []string{"hunter2", "s3cret", "abc123"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("nested %q reached the writer in cleartext: %s", secret, out)
+		}
+	}
+	if strings.Count(out, RedactedValue) != 3 {
+		t.Errorf("want 3 redacted values, got: %s", out)
+	}
+	if !strings.Contains(out, `"user":"alice"`) || !strings.Contains(out, `"User":"bob"`) {
+		t.Errorf("non-sensitive nested fields were dropped: %s", out)
+	}
+}
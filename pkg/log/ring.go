@@ -0,0 +1,106 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultRingBufferSize number of entries a RingBuffer keeps if no size is given
+const DefaultRingBufferSize = 1000
+
+// RingBuffer hook for logrus which keeps the most recent entries in memory, so
+// they can be inspected through its http.Handler without access to log files.
+// Register it with WithHooks or AddHook.
+type RingBuffer struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+// NewRingBuffer returns a RingBuffer keeping the last size entries
+func NewRingBuffer(size int) *RingBuffer {
+	if size <= 0 {
+		size = DefaultRingBufferSize
+	}
+	return &RingBuffer{entries: make([]Entry, size)}
+}
+
+// Fire func used by logrus to keep the entry, replacing the oldest one if the
+// buffer is full. Errors are kept as their message so they can be encoded.
+func (r *RingBuffer) Fire(entry *logrus.Entry) error {
+	e := newEntry(entry)
+	for k, v := range e.Fields {
+		if err, ok := v.(error); ok {
+			e.Fields[k] = err.Error()
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+	return nil
+}
+
+// Levels defines in which log levels the ring buffer hook works
+func (r *RingBuffer) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Entries returns the kept entries, oldest first
+func (r *RingBuffer) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		entries := make([]Entry, r.next)
+		copy(entries, r.entries[:r.next])
+		return entries
+	}
+
+	entries := make([]Entry, 0, len(r.entries))
+	entries = append(entries, r.entries[r.next:]...)
+	return append(entries, r.entries[:r.next]...)
+}
+
+// ServeHTTP responds with the kept entries as JSON array, or as logfmt lines if
+// the format query parameter is "text"
+func (r *RingBuffer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	entries := r.Entries()
+
+	if strings.EqualFold(req.URL.Query().Get("format"), "text") {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		var b bytes.Buffer
+		for _, e := range entries {
+			writeLogfmt(&b, "time", e.Time.Format(timestampFormat))
+			writeLogfmt(&b, "level", string(e.Level))
+			writeLogfmt(&b, "msg", e.Message)
+			keys := make([]string, 0, len(e.Fields))
+			for k := range e.Fields {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				writeLogfmt(&b, k, e.Fields[k])
+			}
+			b.WriteByte('\n')
+			_, _ = w.Write(b.Bytes())
+			b.Reset()
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(entries)
+}
@@ -0,0 +1,58 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRingBufferKeepsLastEntries(t *testing.T) {
+	ring := NewRingBuffer(5)
+	l := NewWithOptions(WithOutput(ioutil.Discard), WithHooks(ring))
+
+	for i := 1; i <= 3; i++ {
+		l.Warnf("entry %d", i)
+	}
+	if got := len(ring.Entries()); got != 3 {
+		t.Fatalf("kept %d entries before the buffer was full, want 3", got)
+	}
+
+	for i := 4; i <= 12; i++ {
+		l.Warnf("entry %d", i)
+	}
+	entries := ring.Entries()
+	if len(entries) != 5 {
+		t.Fatalf("kept %d entries, want exactly 5", len(entries))
+	}
+	for i, e := range entries {
+		if want := fmt.Sprintf("entry %d", i+8); e.Message != want {
+			t.Errorf("entry %d = %q, want %q", i, e.Message, want)
+		}
+	}
+}
+
+func TestRingBufferHandler(t *testing.T) {
+	ring := NewRingBuffer(2)
+	l := NewWithOptions(WithOutput(ioutil.Discard), WithHooks(ring))
+	l.WithError(fmt.Errorf("boom")).Error("failed")
+
+	rec := httptest.NewRecorder()
+	ring.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/logs", nil))
+	var entries []Entry
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("invalid JSON response %q: %v", rec.Body.String(), err)
+	}
+	if len(entries) != 1 || entries[0].Message != "failed" || entries[0].Fields[ErrorKey] != "boom" {
+		t.Errorf("got entries %+v", entries)
+	}
+
+	rec = httptest.NewRecorder()
+	ring.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/logs?format=text", nil))
+	if !strings.Contains(rec.Body.String(), "failed") {
+		t.Errorf("text response doesn't contain the entry: %q", rec.Body.String())
+	}
+}
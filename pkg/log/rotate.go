@@ -0,0 +1,223 @@
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultRotationMaxSize size in megabytes a log file grows to before it is rotated
+	DefaultRotationMaxSize = 100
+	// DefaultRotationMaxBackups number of rotated log files that are kept
+	DefaultRotationMaxBackups = 3
+)
+
+// FileRotation configures size based rotation of a log file
+type FileRotation struct {
+	// MaxSize in megabytes the file may reach before it is rotated, zero
+	// disables rotation.
+	MaxSize int
+	// MaxBackups is the number of rotated files to keep.
+	MaxBackups int
+	// Compress rotated files with gzip.
+	Compress bool
+}
+
+// rotatingFile is an io.Writer for a file which is rolled over once it
+// exceeds the configured size
+type rotatingFile struct {
+	mu          sync.Mutex
+	path        string
+	flag        int
+	chmod       os.FileMode
+	rotation    FileRotation
+	file        *os.File
+	size        int64
+	closed      bool
+	compressing sync.WaitGroup
+}
+
+// newRotatingFile opens the file at path for rotated writing
+func newRotatingFile(path string, flag int, chmod os.FileMode, rotation FileRotation) (*rotatingFile, error) {
+	r := &rotatingFile{
+		path:     path,
+		flag:     flag,
+		chmod:    chmod,
+		rotation: rotation,
+	}
+	if err := r.open(flag); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write writes p to the file, rotating it first if p would exceed the max size.
+// A single write is never split across two files.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	// a failed rotation couldn't reopen the file, try again
+	if r.file == nil {
+		if err := r.open(r.flag &^ os.O_TRUNC); err != nil {
+			return 0, err
+		}
+	}
+
+	if r.rotation.MaxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxBytes() {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close flushes and closes the underlying file and waits until the rotated
+// files are compressed
+func (r *rotatingFile) Close() error {
+	defer r.compressing.Wait()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Sync()
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	r.file = nil
+	return err
+}
+
+func (r *rotatingFile) maxBytes() int64 {
+	return int64(r.rotation.MaxSize) * 1024 * 1024
+}
+
+func (r *rotatingFile) open(flag int) error {
+	file, err := os.OpenFile(r.path, flag, r.chmod)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// rotate closes the current file, shifts the existing backups and reopens a
+// fresh file at the original path. If the rotation fails the current file is
+// reopened for appending, so later writes don't fail as well.
+func (r *rotatingFile) rotate() error {
+	err := r.file.Close()
+	if err != nil {
+		err = errors.Wrap(err, "failed to close log file for rotation")
+	} else {
+		err = r.shift()
+	}
+
+	flag := r.flag
+	if err != nil {
+		flag &^= os.O_TRUNC
+	}
+	if openErr := r.open(flag); openErr != nil {
+		r.file = nil
+		if err == nil {
+			err = openErr
+		}
+	}
+	return err
+}
+
+// shift moves the current file to the first backup and the existing backups one
+// further, dropping the oldest one. The new backup is compressed in the
+// background, so writes aren't blocked while it is gzipped.
+func (r *rotatingFile) shift() error {
+	// a backup which is still being compressed must not be moved
+	r.compressing.Wait()
+
+	for i := r.rotation.MaxBackups; i > 0; i-- {
+		for _, ext := range []string{"", ".gz"} {
+			src := r.backupName(i) + ext
+			if _, err := os.Stat(src); err != nil {
+				continue
+			}
+			if i == r.rotation.MaxBackups {
+				_ = os.Remove(src)
+				continue
+			}
+			if err := os.Rename(src, r.backupName(i+1)+ext); err != nil {
+				return errors.Wrap(err, "failed to shift rotated log file")
+			}
+		}
+	}
+
+	if r.rotation.MaxBackups == 0 {
+		return errors.Wrap(os.Remove(r.path), "failed to remove log file")
+	}
+
+	backup := r.backupName(1)
+	if err := os.Rename(r.path, backup); err != nil {
+		return errors.Wrap(err, "failed to rotate log file")
+	}
+	if r.rotation.Compress {
+		r.compressing.Add(1)
+		go func() {
+			defer r.compressing.Done()
+			if err := compressFile(backup); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "unable to compress rotated log file %s: %v", backup, err)
+			}
+		}()
+	}
+	return nil
+}
+
+func (r *rotatingFile) backupName(i int) string {
+	return fmt.Sprintf("%s.%d", r.path, i)
+}
+
+// compressFile gzips the file at path into path.gz and removes the original
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = gz.Close()
+		_ = dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,118 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// chunk returns a write of 600 KiB, so every second write exceeds 1 MB
+func chunk(c byte) []byte {
+	return bytes.Repeat([]byte{c}, 600*1024)
+}
+
+func newTestRotatingFile(t *testing.T, rotation FileRotation) (*rotatingFile, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.log")
+	r, err := newRotatingFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644, rotation)
+	if err != nil {
+		t.Fatalf("failed to open rotating file: %v", err)
+	}
+	return r, path
+}
+
+func assertContent(t *testing.T, path string, want []byte) {
+	t.Helper()
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s has %d bytes starting with %q, want %d bytes of %q", path, len(got), got[:1], len(want), want[:1])
+	}
+}
+
+func TestRotation(t *testing.T) {
+	r, path := newTestRotatingFile(t, FileRotation{MaxSize: 1, MaxBackups: 2})
+
+	for _, c := range []byte("abcd") {
+		if _, err := r.Write(chunk(c)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	assertContent(t, path, chunk('d'))
+	assertContent(t, path+".1", chunk('c'))
+	assertContent(t, path+".2", chunk('b'))
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("more backups than MaxBackups were kept: %v", err)
+	}
+}
+
+func TestRotationCompress(t *testing.T) {
+	r, path := newTestRotatingFile(t, FileRotation{MaxSize: 1, MaxBackups: 1, Compress: true})
+
+	for _, c := range []byte("ab") {
+		if _, err := r.Write(chunk(c)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	f, err := os.Open(path + ".1.gz")
+	if err != nil {
+		t.Fatalf("compressed backup is missing: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("invalid gzip backup: %v", err)
+	}
+	content, err := ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("failed to decompress backup: %v", err)
+	}
+	if !bytes.Equal(content, chunk('a')) {
+		t.Errorf("compressed backup has %d bytes, want the first write", len(content))
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Errorf("uncompressed backup wasn't removed: %v", err)
+	}
+}
+
+func TestRotationFailureKeepsWriting(t *testing.T) {
+	r, path := newTestRotatingFile(t, FileRotation{MaxSize: 1, MaxBackups: 1})
+	defer r.Close()
+
+	// a non-empty directory in place of the backup makes the rename fail
+	if err := os.MkdirAll(filepath.Join(path+".1", "blocked"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Write(chunk('a')); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, err := r.Write(chunk('b')); err == nil {
+		t.Fatal("Write succeeded, want the rotation error")
+	}
+	if _, err := r.Write([]byte("c")); err != nil {
+		t.Fatalf("Write after the failed rotation failed: %v", err)
+	}
+
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Write(chunk('d')); err != nil {
+		t.Fatalf("Write rotating the file again failed: %v", err)
+	}
+	assertContent(t, path+".1", append(chunk('a'), 'c'))
+	assertContent(t, path, chunk('d'))
+}
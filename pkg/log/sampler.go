@@ -0,0 +1,56 @@
+package log
+
+import (
+	"sync"
+	"time"
+)
+
+// sampler handler which logs the first entries of a message per window and
+// only every nth one after that
+type sampler struct {
+	mu          sync.Mutex
+	first       int
+	thereafter  int
+	window      time.Duration
+	windowStart time.Time
+	counts      map[string]int
+}
+
+// NewSampled returns a logger which logs the first occurrences of each message
+// per level within a window and after that only every thereafter-th one, a
+// thereafter of zero drops all further occurrences. Loggers derived through
+// WithFields and the like share the counts. Fatal and panic entries are never
+// dropped.
+func NewSampled(l Logger, first int, thereafter int, window time.Duration) Logger {
+	return wrap(l, &sampler{
+		first:      first,
+		thereafter: thereafter,
+		window:     window,
+		counts:     make(map[string]int),
+	})
+}
+
+func (s *sampler) handle(l Logger, level Level, msg string) {
+	if s.sample(string(level) + "|" + msg) {
+		logAt(l, level, msg)
+	}
+}
+
+// sample counts the occurrence of key and reports whether it should be logged
+func (s *sampler) sample(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.windowStart) >= s.window {
+		s.windowStart = now
+		s.counts = make(map[string]int)
+	}
+
+	s.counts[key]++
+	n := s.counts[key]
+	if n <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
@@ -0,0 +1,49 @@
+package log
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestSampled(t *testing.T) {
+	observer, logs := NewObserver()
+	l := NewSampled(observer, 10, 100, time.Hour)
+
+	for i := 0; i < 1000; i++ {
+		l.Info("cache miss")
+	}
+
+	// the first 10, then the 110th, 210th, ... 910th
+	if got := logs.Len(); got != 19 {
+		t.Errorf("logged %d of 1000 entries, want 19", got)
+	}
+}
+
+func TestSampledSharesCountsWithDerivedLoggers(t *testing.T) {
+	observer, logs := NewObserver()
+	l := NewSampled(observer, 2, 0, time.Hour)
+
+	l.Info("cache miss")
+	l.WithFields(Fields{"key": "a"}).Info("cache miss")
+	l.WithFields(Fields{"key": "b"}).Info("cache miss")
+	l.Warn("cache miss")
+
+	if got := logs.Len(); got != 3 {
+		t.Errorf("logged %d entries, want 3", got)
+	}
+	if got := len(logs.FilterField("key", "a")); got != 1 {
+		t.Errorf("derived logger lost its fields, got %d entries with key=a", got)
+	}
+}
+
+func BenchmarkSampled(b *testing.B) {
+	l := NewSampled(NewWithOptions(WithOutput(ioutil.Discard)), 100, 100, time.Second)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Info("cache miss")
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package log
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// FlushOnShutdown closes the logger when the process receives SIGTERM or
+// SIGINT, so async buffers and files are flushed before it exits, e.g. during
+// a rolling deploy. Afterwards the signal is raised again to terminate the
+// process as it would have without the handler. Applications handling these
+// signals themselves should call Close during their own shutdown instead. The
+// returned function uninstalls the handler.
+func FlushOnShutdown(l Logger) func() {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+			_ = l.Close()
+
+			process, err := os.FindProcess(os.Getpid())
+			if err == nil {
+				err = process.Signal(sig)
+			}
+			if err != nil {
+				os.Exit(1)
+			}
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+//go:build !windows
+
+package log
+
+import (
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// closeHook signals on closed when the logger it is registered on is closed
+type closeHook struct {
+	closed chan struct{}
+}
+
+func (h *closeHook) Levels() []logrus.Level   { return logrus.AllLevels }
+func (h *closeHook) Fire(*logrus.Entry) error { return nil }
+func (h *closeHook) Close() error             { close(h.closed); return nil }
+
+func TestFlushOnShutdown(t *testing.T) {
+	// keeps the signal raised again by the handler from terminating the test
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	hook := &closeHook{closed: make(chan struct{})}
+	l := NewWithOptions(WithOutput(ioutil.Discard), WithHooks(hook))
+	stop := FlushOnShutdown(l)
+	defer stop()
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("failed to send SIGTERM: %v", err)
+	}
+	select {
+	case <-hook.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("logger wasn't closed on SIGTERM")
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-signals:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d SIGTERM, want the original one and the one raised again", i)
+		}
+	}
+}
+
+func TestFlushOnShutdownUninstalled(t *testing.T) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	hook := &closeHook{closed: make(chan struct{})}
+	l := NewWithOptions(WithOutput(ioutil.Discard), WithHooks(hook))
+	FlushOnShutdown(l)()
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("failed to send SIGTERM: %v", err)
+	}
+	<-signals
+	select {
+	case <-hook.closed:
+		t.Error("logger was closed after the handler was uninstalled")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,49 @@
+//go:build !windows && !plan9
+
+package log
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// InstallSignalHandlers raises the level of the logger to debug on SIGUSR1 and
+// restores the level it had before on SIGUSR2, which is the level it was
+// configured with and not necessarily info. The returned function uninstalls
+// the handlers.
+func InstallSignalHandlers(l Logger) func() {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		var previous Level
+		for {
+			select {
+			case sig := <-signals:
+				switch {
+				case sig == syscall.SIGUSR1 && previous == "":
+					previous = l.Level()
+					l.SetLevel(LevelDebug)
+					l.Infof("received %s, raised log level from %s to %s", sig, previous, LevelDebug)
+				case sig == syscall.SIGUSR2 && previous != "":
+					l.Infof("received %s, restoring log level %s", sig, previous)
+					l.SetLevel(previous)
+					previous = ""
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,9 @@
+//go:build windows || plan9
+
+package log
+
+// InstallSignalHandlers does nothing as SIGUSR1 and SIGUSR2 don't exist on this
+// platform
+func InstallSignalHandlers(l Logger) func() {
+	return func() {}
+}
@@ -0,0 +1,108 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// slogHandler slog.Handler which forwards records to a Logger
+type slogHandler struct {
+	logger Logger
+	groups []string
+	attrs  Fields
+}
+
+// NewSlogHandler returns a slog.Handler which emits records through the given
+// logger, so libraries logging with log/slog share its formatting and hooks.
+// Attributes of groups are logged with the group names as dotted key prefix.
+func NewSlogHandler(l Logger) slog.Handler {
+	return &slogHandler{logger: l, attrs: Fields{}}
+}
+
+// Enabled reports whether the logger emits records of the given level
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Enabled(fromSlogLevel(level))
+}
+
+// Handle emits the record with its attributes as fields
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make(Fields, len(h.attrs)+r.NumAttrs())
+	for k, v := range h.attrs {
+		fields[k] = v
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addSlogAttr(fields, h.groups, a)
+		return true
+	})
+
+	logger := h.logger
+	if ctx != nil {
+		logger = logger.WithContext(ctx)
+	}
+	logAt(logger.WithFields(fields), fromSlogLevel(r.Level), r.Message)
+	return nil
+}
+
+// WithAttrs returns a handler which adds the attributes to every record
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make(Fields, len(h.attrs)+len(attrs))
+	for k, v := range h.attrs {
+		fields[k] = v
+	}
+	for _, a := range attrs {
+		addSlogAttr(fields, h.groups, a)
+	}
+	return &slogHandler{logger: h.logger, groups: h.groups, attrs: fields}
+}
+
+// WithGroup returns a handler which prefixes the keys of following attributes
+// with the group name
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]string, len(h.groups), len(h.groups)+1)
+	copy(groups, h.groups)
+	return &slogHandler{logger: h.logger, groups: append(groups, name), attrs: h.attrs}
+}
+
+// addSlogAttr adds the attribute to fields, flattening groups into dotted keys
+func addSlogAttr(fields Fields, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(groups[:len(groups):len(groups)], a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			addSlogAttr(fields, groups, ga)
+		}
+		return
+	}
+
+	key := a.Key
+	if len(groups) > 0 {
+		key = strings.Join(groups, ".") + "." + key
+	}
+	fields[key] = a.Value.Any()
+}
+
+// fromSlogLevel maps a slog level to the matching Level constant
+func fromSlogLevel(level slog.Level) Level {
+	switch {
+	case level < slog.LevelDebug:
+		return LevelTrace
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarn
+	default:
+		return LevelError
+	}
+}
@@ -0,0 +1,69 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestSlogLevels(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  Level
+	}{
+		{slog.LevelDebug - 4, LevelTrace},
+		{slog.LevelDebug, LevelDebug},
+		{slog.LevelInfo, LevelInfo},
+		{slog.LevelInfo + 2, LevelInfo},
+		{slog.LevelWarn, LevelWarn},
+		{slog.LevelError, LevelError},
+		{slog.LevelError + 4, LevelError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			l, logs := NewObserver()
+			slog.New(NewSlogHandler(l)).Log(context.Background(), tt.level, "message")
+
+			entries := logs.All()
+			if len(entries) != 1 || entries[0].Level != tt.want {
+				t.Errorf("logged %v, want one entry at %q", entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlogEnabled(t *testing.T) {
+	l, logs := NewObserver()
+	l.SetLevel(LevelWarn)
+	logger := slog.New(NewSlogHandler(l))
+
+	if logger.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("info is enabled on a warn logger")
+	}
+	logger.Info("dropped")
+	logger.Warn("kept")
+	if logs.Len() != 1 {
+		t.Errorf("logged %v, want only the warning", logs.All())
+	}
+}
+
+func TestSlogAttrs(t *testing.T) {
+	l, logs := NewObserver()
+	logger := slog.New(NewSlogHandler(l)).With("service", "users").WithGroup("req")
+
+	logger.Info("handled", "status", 200, slog.Group("user", "id", 7))
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(entries))
+	}
+	want := Fields{"service": "users", "req.status": int64(200), "req.user.id": int64(7)}
+	for k, v := range want {
+		if got := entries[0].Fields[k]; got != v {
+			t.Errorf("%s = %#v, want %#v", k, got, v)
+		}
+	}
+	if entries[0].Message != "handled" {
+		t.Errorf("message = %q", entries[0].Message)
+	}
+}
@@ -0,0 +1,45 @@
+package log
+
+import (
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// entryCounts counts the entries emitted by all loggers, indexed by logrus level
+var entryCounts [logrus.TraceLevel + 1]atomic.Uint64
+
+// Stats returns the number of entries emitted per level by all loggers created
+// through this package since the start of the process or the last ResetStats,
+// e.g. to expose them as a JSON metrics blob or assert on them in tests.
+// Levels without entries are included with a count of zero.
+func Stats() map[Level]uint64 {
+	stats := make(map[Level]uint64, len(entryCounts))
+	for i := range entryCounts {
+		stats[fromLogrusLevel(logrus.Level(i))] = entryCounts[i].Load()
+	}
+	return stats
+}
+
+// ResetStats sets the counts returned by Stats back to zero
+func ResetStats() {
+	for i := range entryCounts {
+		entryCounts[i].Store(0)
+	}
+}
+
+// statsHook hook for logrus to count the emitted entries for Stats
+type statsHook struct{}
+
+// Fire func used by logrus to count the entry
+func (statsHook) Fire(entry *logrus.Entry) error {
+	if int(entry.Level) < len(entryCounts) {
+		entryCounts[entry.Level].Add(1)
+	}
+	return nil
+}
+
+// Levels defines in which log levels the stats hook works
+func (statsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
@@ -0,0 +1,46 @@
+package log
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	ResetStats()
+	defer ResetStats()
+
+	l := NewWithOptions(WithOutput(ioutil.Discard), WithLevel(LevelInfo))
+	for i := 0; i < 3; i++ {
+		l.Info("request handled")
+	}
+	l.Warn("slow request")
+	l.WithPrefix("db").Errorf("query failed")
+	l.Error("request failed")
+	l.Debug("below the level, not emitted")
+
+	want := map[Level]uint64{
+		LevelTrace: 0,
+		LevelDebug: 0,
+		LevelInfo:  3,
+		LevelWarn:  1,
+		LevelError: 2,
+		LevelFatal: 0,
+		LevelPanic: 0,
+	}
+	stats := Stats()
+	if len(stats) != len(want) {
+		t.Errorf("Stats() = %v, want all levels", stats)
+	}
+	for level, count := range want {
+		if stats[level] != count {
+			t.Errorf("Stats()[%s] = %d, want %d", level, stats[level], count)
+		}
+	}
+
+	ResetStats()
+	for level, count := range Stats() {
+		if count != 0 {
+			t.Errorf("Stats()[%s] = %d after ResetStats, want 0", level, count)
+		}
+	}
+}
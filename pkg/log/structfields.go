@@ -0,0 +1,91 @@
+package log
+
+import (
+	"reflect"
+	"strings"
+)
+
+// structFields returns the exported fields of the struct v points to or holds
+// as log fields. The log tag renames a field, "-" skips it, the omitempty
+// option skips zero values and the sensitive option replaces the value with
+// RedactedValue:
+//
+//	type User struct {
+//		ID       int    `log:"user_id"`
+//		Email    string `log:"email,omitempty"`
+//		Password string `log:"password,sensitive"`
+//		Session  string `log:"-"`
+//	}
+//
+// Fields of embedded structs are added as if they were fields of v. A value
+// which is not a struct is logged under "value".
+func structFields(v interface{}) Fields {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		if !rv.IsValid() {
+			return nil
+		}
+		return Fields{"value": rv.Interface()}
+	}
+
+	fields := make(Fields, rv.NumField())
+	addStructFields(fields, rv)
+	return fields
+}
+
+func addStructFields(fields Fields, rv reflect.Value) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := sf.Tag.Get("log")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fv := rv.Field(i)
+		if sf.Anonymous && name == "" {
+			embedded := fv
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				addStructFields(fields, embedded)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		omitEmpty, sensitive := false, false
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				omitEmpty = true
+			case "sensitive":
+				sensitive = true
+			}
+		}
+		if omitEmpty && fv.IsZero() {
+			continue
+		}
+		if sensitive {
+			fields[name] = RedactedValue
+			continue
+		}
+		fields[name] = fv.Interface()
+	}
+}
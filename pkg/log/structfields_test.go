@@ -0,0 +1,86 @@
+package log
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testAudit struct {
+	Source string
+}
+
+type testUser struct {
+	testAudit
+	ID       int `log:"user_id"`
+	Name     string
+	Email    string `log:"email,omitempty"`
+	Password string `log:"password,sensitive"`
+	Session  string `log:"-"`
+	internal string
+}
+
+func TestWithStruct(t *testing.T) {
+	tests := []struct {
+		name string
+		user interface{}
+		want Fields
+	}{
+		{
+			name: "all set",
+			user: testUser{
+				testAudit: testAudit{Source: "api"},
+				ID:        7,
+				Name:      "alice",
+				Email:     "alice@example.com",
+				Password:  "hunter2",
+				Session:   "abc",
+				internal:  "x",
+			},
+			want: Fields{
+				"Source":   "api",
+				"user_id":  7,
+				"Name":     "alice",
+				"email":    "alice@example.com",
+				"password": RedactedValue,
+			},
+		},
+		{
+			name: "omitempty",
+			user: &testUser{ID: 8, Name: "bob"},
+			want: Fields{
+				"Source":   "",
+				"user_id":  8,
+				"Name":     "bob",
+				"password": RedactedValue,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, logs := NewObserver()
+			l.WithStruct(tt.user).Info("user loaded")
+
+			entries := logs.All()
+			if len(entries) != 1 {
+				t.Fatalf("logged %d entries, want 1", len(entries))
+			}
+			if !reflect.DeepEqual(entries[0].Fields, tt.want) {
+				t.Errorf("fields = %v, want %v", entries[0].Fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithStructNonStruct(t *testing.T) {
+	l, logs := NewObserver()
+	l.WithStruct(42).Info("number")
+	l.WithStruct((*testUser)(nil)).Info("nil")
+
+	entries := logs.All()
+	if entries[0].Fields["value"] != 42 {
+		t.Errorf("fields of a non-struct = %v, want value=42", entries[0].Fields)
+	}
+	if len(entries[1].Fields) != 0 {
+		t.Errorf("fields of a nil pointer = %v, want none", entries[1].Fields)
+	}
+}
@@ -0,0 +1,62 @@
+//go:build !windows && !plan9
+
+package log
+
+import (
+	"log/syslog"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// LogrusSyslogHook hook for logrus to forward log to syslog
+type LogrusSyslogHook struct {
+	writer    *syslog.Writer
+	formatter logrus.Formatter
+}
+
+// NewSyslogHook returns new syslog hook object for logrus. An empty network and
+// addr connect to the local syslog daemon.
+func NewSyslogHook(network, addr, tag string) (*LogrusSyslogHook, error) {
+	writer, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LogrusSyslogHook{writer, getFormatter(true)}, nil
+}
+
+// Fire func used by logrus to forward the log to syslog with the severity
+// matching its level
+func (hook *LogrusSyslogHook) Fire(entry *logrus.Entry) error {
+	b, err := hook.formatter.Format(entry)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	msg := string(b)
+
+	switch entry.Level {
+	case logrus.PanicLevel:
+		return hook.writer.Emerg(msg)
+	case logrus.FatalLevel:
+		return hook.writer.Crit(msg)
+	case logrus.ErrorLevel:
+		return hook.writer.Err(msg)
+	case logrus.WarnLevel:
+		return hook.writer.Warning(msg)
+	case logrus.InfoLevel:
+		return hook.writer.Info(msg)
+	default:
+		return hook.writer.Debug(msg)
+	}
+}
+
+// Levels defines in which log levels the syslog hook works
+func (hook *LogrusSyslogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Close closes the connection to syslog
+func (hook *LogrusSyslogHook) Close() error {
+	return hook.writer.Close()
+}
@@ -0,0 +1,33 @@
+//go:build windows || plan9
+
+package log
+
+import (
+	"errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogrusSyslogHook hook for logrus to forward log to syslog, syslog is not
+// supported on this platform
+type LogrusSyslogHook struct{}
+
+// NewSyslogHook always fails as syslog is not supported on this platform
+func NewSyslogHook(network, addr, tag string) (*LogrusSyslogHook, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
+
+// Fire func used by logrus, it does nothing
+func (hook *LogrusSyslogHook) Fire(entry *logrus.Entry) error {
+	return nil
+}
+
+// Levels defines in which log levels the syslog hook works
+func (hook *LogrusSyslogHook) Levels() []logrus.Level {
+	return nil
+}
+
+// Close does nothing
+func (hook *LogrusSyslogHook) Close() error {
+	return nil
+}
@@ -0,0 +1,48 @@
+package log
+
+// ColorTheme sets the colors of the text format. A color is either an ansi
+// style like "red" or "yellow+b", a 256 color code like "208", or a 24-bit
+// color like "#ff8800". Empty colors keep the default, the whole theme is
+// ignored when colors are disabled.
+type ColorTheme struct {
+	Trace     string
+	Debug     string
+	Info      string
+	Warn      string
+	Error     string
+	Fatal     string
+	Panic     string
+	Prefix    string
+	Timestamp string
+	// Fields colors the field keys, they take the color of the level if empty.
+	Fields string
+}
+
+// DefaultColorTheme is the theme used if none is set
+var DefaultColorTheme = ColorTheme{
+	Trace:     defaultColorScheme.TraceLevelStyle,
+	Debug:     defaultColorScheme.DebugLevelStyle,
+	Info:      defaultColorScheme.InfoLevelStyle,
+	Warn:      defaultColorScheme.WarnLevelStyle,
+	Error:     defaultColorScheme.ErrorLevelStyle,
+	Fatal:     defaultColorScheme.FatalLevelStyle,
+	Panic:     defaultColorScheme.PanicLevelStyle,
+	Prefix:    defaultColorScheme.PrefixStyle,
+	Timestamp: defaultColorScheme.TimestampStyle,
+}
+
+// scheme returns the color scheme of the text formatter for the theme
+func (t ColorTheme) scheme() *colorScheme {
+	return &colorScheme{
+		InfoLevelStyle:  t.Info,
+		WarnLevelStyle:  t.Warn,
+		ErrorLevelStyle: t.Error,
+		FatalLevelStyle: t.Fatal,
+		PanicLevelStyle: t.Panic,
+		DebugLevelStyle: t.Debug,
+		TraceLevelStyle: t.Trace,
+		PrefixStyle:     t.Prefix,
+		TimestampStyle:  t.Timestamp,
+		FieldStyle:      t.Fields,
+	}
+}
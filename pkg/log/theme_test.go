@@ -0,0 +1,37 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestColorThemeEscapes(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithOptions(
+		WithOutput(&buf),
+		WithColors(true),
+		WithColorTheme(ColorTheme{Info: "#ff8800", Warn: "yellow+b", Prefix: "208"}),
+	)
+
+	l.WithPrefix("db").Info("connected")
+	l.Warn("slow")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+	}
+	checks := []struct {
+		line int
+		want string
+	}{
+		{0, "\x1b[38;2;255;136;0m INFO\x1b[0m"},
+		{0, "\x1b[0;38;5;208m db:\x1b[0m"},
+		{1, "\x1b[0;1;33m WARN\x1b[0m"},
+	}
+	for _, c := range checks {
+		if !strings.Contains(lines[c.line], c.want) {
+			t.Errorf("line %q doesn't contain %q", lines[c.line], c.want)
+		}
+	}
+}
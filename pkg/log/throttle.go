@@ -0,0 +1,87 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// throttler handler which collapses consecutive identical entries into the
+// first one and a summary of how often it was repeated
+type throttler struct {
+	mu      sync.Mutex
+	timeout time.Duration
+	pending *repeatedEntry
+}
+
+// repeatedEntry is the entry currently being repeated
+type repeatedEntry struct {
+	key    string
+	logger Logger
+	level  Level
+	msg    string
+	count  int
+	timer  *time.Timer
+}
+
+// NewThrottled returns a logger which logs the first of consecutive identical
+// messages on the same level and suppresses the repetitions. Once a different
+// message is logged, the timeout elapses or the logger is closed a summary
+// with the number of repetitions is logged. Only the latest message is
+// tracked, so memory does not grow with the number of distinct messages.
+func NewThrottled(l Logger, timeout time.Duration) Logger {
+	return wrap(l, &throttler{timeout: timeout})
+}
+
+func (t *throttler) handle(l Logger, level Level, msg string) {
+	key := string(level) + "|" + msg
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.pending != nil && t.pending.key == key {
+		t.pending.count++
+		if t.pending.timer == nil {
+			pending := t.pending
+			t.pending.timer = time.AfterFunc(t.timeout, func() { t.expire(pending) })
+		}
+		return
+	}
+
+	t.flush()
+	t.pending = &repeatedEntry{key: key, logger: l, level: level, msg: msg}
+	logAt(l, level, msg)
+}
+
+// expire flushes the pending entry if it is still the given one
+func (t *throttler) expire(pending *repeatedEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.pending == pending {
+		t.flush()
+	}
+}
+
+// flush logs the summary of the pending entry, must be called with the lock held
+func (t *throttler) flush() {
+	if t.pending == nil {
+		return
+	}
+	if t.pending.timer != nil {
+		t.pending.timer.Stop()
+	}
+	if t.pending.count > 0 {
+		logAt(t.pending.logger, t.pending.level, fmt.Sprintf("%s (repeated %d times)", t.pending.msg, t.pending.count))
+	}
+	t.pending = nil
+}
+
+// Close logs the summary of a pending entry
+func (t *throttler) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.flush()
+	return nil
+}
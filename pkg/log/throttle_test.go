@@ -0,0 +1,70 @@
+package log
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestThrottledRepeats(t *testing.T) {
+	observer, logs := NewObserver()
+	l := NewThrottled(observer, time.Hour)
+
+	for i := 0; i < 10; i++ {
+		l.Errorf("connection to %s refused", "db")
+	}
+	if got := logs.Len(); got != 1 {
+		t.Fatalf("logged %d entries before the summary, want 1", got)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries := logs.FilterLevel(LevelError)
+	if len(entries) != 2 {
+		t.Fatalf("logged %d entries, want the first one and a summary", len(entries))
+	}
+	if entries[0].Message != "connection to db refused" {
+		t.Errorf("first entry = %q", entries[0].Message)
+	}
+	if want := "connection to db refused (repeated 9 times)"; entries[1].Message != want {
+		t.Errorf("summary = %q, want %q", entries[1].Message, want)
+	}
+}
+
+func TestThrottledFlushesOnDifferentMessage(t *testing.T) {
+	observer, logs := NewObserver()
+	l := NewThrottled(observer, time.Hour)
+
+	l.Warn("slow query")
+	l.Warn("slow query")
+	l.Warn("slow query")
+	l.Info("query done")
+
+	var msgs []string
+	for _, e := range logs.All() {
+		msgs = append(msgs, e.Message)
+	}
+	want := []string{"slow query", "slow query (repeated 2 times)", "query done"}
+	if len(msgs) != len(want) {
+		t.Fatalf("logged %q, want %q", msgs, want)
+	}
+	for i := range want {
+		if msgs[i] != want[i] {
+			t.Errorf("entry %d = %q, want %q", i, msgs[i], want[i])
+		}
+	}
+}
+
+func BenchmarkThrottled(b *testing.B) {
+	l := NewThrottled(NewWithOptions(WithOutput(ioutil.Discard)), time.Second)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Error("connection refused")
+		}
+	})
+	b.StopTimer()
+	_ = l.Close()
+}
@@ -0,0 +1,44 @@
+package log
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Clock provides the time entries are logged at
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock Clock which returns the current time
+type realClock struct{}
+
+// Now returns the current local time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// timeHook hook for logrus which sets the time of every entry from the clock,
+// optionally converted to UTC, so every formatter and output renders the same
+// time
+type timeHook struct {
+	clock Clock
+	utc   bool
+}
+
+// Fire func used by logrus to set the time of the entry
+func (hook *timeHook) Fire(entry *logrus.Entry) error {
+	if hook.clock != nil {
+		entry.Time = hook.clock.Now()
+	}
+	if hook.utc {
+		entry.Time = entry.Time.UTC()
+	}
+	return nil
+}
+
+// Levels defines in which log levels the time hook works
+func (hook *timeHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
@@ -0,0 +1,69 @@
+package log
+
+import (
+	"time"
+)
+
+const (
+	// OperationKey field the operation name of a Timer is logged under
+	OperationKey = "operation"
+	// DurationMSKey field the duration of a Timer is logged under in milliseconds
+	DurationMSKey = "duration_ms"
+)
+
+// Timer measures the duration of an operation and logs it when stopped
+//
+//	timer := logger.StartTimer("db.query")
+//	defer timer.Stop()
+type Timer struct {
+	logger    Logger
+	clock     Clock
+	operation string
+	start     time.Time
+	level     Level
+	threshold time.Duration
+}
+
+// newTimer returns a timer for the operation started now
+func newTimer(l Logger, clock Clock, operation string) *Timer {
+	if clock == nil {
+		clock = realClock{}
+	}
+	return &Timer{
+		logger:    l,
+		clock:     clock,
+		operation: operation,
+		start:     clock.Now(),
+		level:     LevelDebug,
+	}
+}
+
+// AtLevel sets the level the duration is logged at, LevelDebug by default
+func (t *Timer) AtLevel(level Level) *Timer {
+	t.level = level
+	return t
+}
+
+// WarnAfter logs the duration at LevelWarn if it exceeds the threshold
+func (t *Timer) WarnAfter(threshold time.Duration) *Timer {
+	t.threshold = threshold
+	return t
+}
+
+// Stop logs the operation with the duration since the timer was started and
+// returns the duration
+func (t *Timer) Stop() time.Duration {
+	duration := t.clock.Now().Sub(t.start)
+
+	level := t.level
+	if t.threshold > 0 && duration > t.threshold {
+		level = LevelWarn
+	}
+	if t.logger.Enabled(level) {
+		logAt(t.logger.WithFields(Fields{
+			OperationKey:  t.operation,
+			DurationMSKey: float64(duration) / float64(time.Millisecond),
+		}), level, t.operation+" finished")
+	}
+	return duration
+}
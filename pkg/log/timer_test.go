@@ -0,0 +1,59 @@
+package log
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+// manualClock Clock which only moves when advanced by the test
+type manualClock struct {
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	return c.now
+}
+
+func TestTimer(t *testing.T) {
+	clock := &manualClock{now: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+	logs := &ObservedLogs{}
+	l := NewWithOptions(
+		WithOutput(ioutil.Discard),
+		WithLevel(LevelTrace),
+		WithClock(clock),
+		WithHooks(&observerHook{logs: logs}),
+	)
+
+	timer := l.StartTimer("db.query")
+	clock.now = clock.now.Add(1500 * time.Microsecond)
+	if d := timer.Stop(); d != 1500*time.Microsecond {
+		t.Errorf("Stop returned %v, want 1.5ms", d)
+	}
+
+	slow := l.StartTimer("db.migrate").WarnAfter(time.Second)
+	clock.now = clock.now.Add(2 * time.Second)
+	slow.Stop()
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	want := []struct {
+		level     Level
+		operation string
+		ms        float64
+	}{
+		{LevelDebug, "db.query", 1.5},
+		{LevelWarn, "db.migrate", 2000},
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Level != w.level || e.Message != w.operation+" finished" {
+			t.Errorf("entry %d: got %s %q, want %s %q", i, e.Level, e.Message, w.level, w.operation+" finished")
+		}
+		if e.Fields[OperationKey] != w.operation || e.Fields[DurationMSKey] != w.ms {
+			t.Errorf("entry %d: fields %v, want %s=%s %s=%v", i, e.Fields, OperationKey, w.operation, DurationMSKey, w.ms)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package log
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+)
+
+// traceIDField is the field the trace id is read from, as logged for the
+// TraceIDKey context value and by otellog.TraceFields
+const traceIDField = "trace_id"
+
+// traceSampler handler which keeps or drops all entries of a trace together
+type traceSampler struct {
+	threshold uint64
+	sampled   func(ctx context.Context) bool
+}
+
+// NewTraceSampled returns a logger which logs the entries of a fraction of the
+// traces, as given by rate between 0 and 1, and drops those of the others. The
+// decision is derived from the trace_id field, so all entries of a trace share
+// it and no request is logged only partly. Traces for which sampled, if not
+// nil, reports true for the context of the logger are always logged, see
+// otellog.NewTraceSampled. Entries without trace id, fatal and panic entries
+// are never dropped.
+//
+//	logger = log.NewTraceSampled(logger, 0.01, nil)
+func NewTraceSampled(l Logger, rate float64, sampled func(ctx context.Context) bool) Logger {
+	var threshold uint64
+	switch {
+	case rate >= 1:
+		threshold = math.MaxUint64
+	case rate > 0:
+		threshold = uint64(rate * math.MaxUint64)
+	}
+	return wrap(l, &traceSampler{threshold: threshold, sampled: sampled})
+}
+
+func (s *traceSampler) handle(l Logger, level Level, msg string) {
+	if s.keep(l) {
+		logAt(l, level, msg)
+	}
+}
+
+// keep reports whether the entries of the trace of the logger are logged
+func (s *traceSampler) keep(l Logger) bool {
+	if s.sampled != nil {
+		if ctx := contextOf(l); ctx != nil && s.sampled(ctx) {
+			return true
+		}
+	}
+
+	traceID, _ := fieldsOf(l)[traceIDField].(string)
+	if traceID == "" {
+		return true
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(traceID))
+	return h.Sum64() < s.threshold || s.threshold == math.MaxUint64
+}
@@ -0,0 +1,53 @@
+package log
+
+import (
+	"context"
+	"testing"
+)
+
+const (
+	// keptTraceID hashes below a rate of 0.5, droppedTraceID above it
+	keptTraceID    = "5b8aa5a2d2c872e8321cf37308d69df2"
+	droppedTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+)
+
+func TestTraceSampledConsistentPerTrace(t *testing.T) {
+	observer, logs := NewObserver()
+	l := NewTraceSampled(observer, 0.5, nil)
+
+	kept := l.WithFields(Fields{traceIDField: keptTraceID})
+	dropped := l.WithFields(Fields{traceIDField: droppedTraceID})
+	for i := 0; i < 10; i++ {
+		kept.WithFields(Fields{"step": i}).Infof("step %d", i)
+		dropped.WithPrefix("db").Debugf("step %d", i)
+		dropped.Errorf("step %d", i)
+	}
+
+	if got := len(logs.FilterField(traceIDField, keptTraceID)); got != 10 {
+		t.Errorf("logged %d of 10 entries of the kept trace", got)
+	}
+	if got := len(logs.FilterField(traceIDField, droppedTraceID)); got != 0 {
+		t.Errorf("logged %d of 20 entries of the dropped trace, want 0", got)
+	}
+}
+
+func TestTraceSampledKeeps(t *testing.T) {
+	ctx := context.WithValue(context.Background(), TraceIDKey, droppedTraceID)
+	sampled := func(ctx context.Context) bool {
+		return ctx.Value(TraceIDKey) != nil
+	}
+	observer, logs := NewObserver()
+	l := NewTraceSampled(observer, 0, sampled)
+
+	l.Info("without trace id")
+	l.WithContext(ctx).WithFields(Fields{traceIDField: droppedTraceID}).Info("sampled trace")
+	l.WithFields(Fields{traceIDField: droppedTraceID}).Info("dropped")
+
+	var msgs []string
+	for _, e := range logs.All() {
+		msgs = append(msgs, e.Message)
+	}
+	if len(msgs) != 2 || msgs[0] != "without trace id" || msgs[1] != "sampled trace" {
+		t.Errorf("logged %q, want the entry without trace id and the sampled trace", msgs)
+	}
+}
@@ -0,0 +1,216 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// handler decides what happens with an entry logged on a wrapped logger, it
+// receives the wrapped logger annotated with all fields of the entry
+type handler interface {
+	handle(l Logger, level Level, msg string)
+}
+
+// flusher is implemented by handlers holding back entries, they are flushed
+// before a fatal entry exits the process
+type flusher interface {
+	flush()
+}
+
+// wrappedLogger Logger which renders every entry and passes it to a handler
+// instead of logging it directly. Derived loggers share the handler.
+type wrappedLogger struct {
+	Logger
+	handler handler
+}
+
+// wrap returns a logger passing the entries logged on l to the handler
+func wrap(l Logger, h handler) Logger {
+	return &wrappedLogger{Logger: l, handler: h}
+}
+
+// log passes the entry to the handler, fatal and panic entries are always
+// logged directly so they can't be dropped and a fatal entry exits even if its
+// level is disabled. Entries held back by the handler are flushed first.
+func (w *wrappedLogger) log(level Level, msg string) {
+	if level == LevelFatal || level == LevelPanic {
+		if f, ok := w.handler.(flusher); ok && level == LevelFatal {
+			f.flush()
+		}
+		logAt(w.Logger, level, msg)
+		return
+	}
+	if !w.Logger.Enabled(level) {
+		return
+	}
+	w.handler.handle(w.Logger, level, msg)
+}
+
+func (w *wrappedLogger) fields() Fields {
+	return fieldsOf(w.Logger)
+}
+
+func (w *wrappedLogger) entryContext() context.Context {
+	return contextOf(w.Logger)
+}
+
+func (w *wrappedLogger) Trace(args ...interface{}) {
+	w.log(LevelTrace, fmt.Sprint(args...))
+}
+
+func (w *wrappedLogger) Traceln(args ...interface{}) {
+	w.log(LevelTrace, sprintln(args...))
+}
+
+func (w *wrappedLogger) Tracef(msg string, args ...interface{}) {
+	w.log(LevelTrace, fmt.Sprintf(msg, args...))
+}
+
+func (w *wrappedLogger) Debug(args ...interface{}) {
+	w.log(LevelDebug, fmt.Sprint(args...))
+}
+
+func (w *wrappedLogger) Debugln(args ...interface{}) {
+	w.log(LevelDebug, sprintln(args...))
+}
+
+func (w *wrappedLogger) Debugf(msg string, args ...interface{}) {
+	w.log(LevelDebug, fmt.Sprintf(msg, args...))
+}
+
+func (w *wrappedLogger) Info(msg string) {
+	w.log(LevelInfo, msg)
+}
+
+func (w *wrappedLogger) Infoln(args ...interface{}) {
+	w.log(LevelInfo, sprintln(args...))
+}
+
+func (w *wrappedLogger) Infof(msg string, args ...interface{}) {
+	w.log(LevelInfo, fmt.Sprintf(msg, args...))
+}
+
+func (w *wrappedLogger) Warn(msg string) {
+	w.log(LevelWarn, msg)
+}
+
+func (w *wrappedLogger) Warnln(args ...interface{}) {
+	w.log(LevelWarn, sprintln(args...))
+}
+
+func (w *wrappedLogger) Warnf(msg string, args ...interface{}) {
+	w.log(LevelWarn, fmt.Sprintf(msg, args...))
+}
+
+func (w *wrappedLogger) Error(msg string) {
+	w.log(LevelError, msg)
+}
+
+func (w *wrappedLogger) Errorln(args ...interface{}) {
+	w.log(LevelError, sprintln(args...))
+}
+
+func (w *wrappedLogger) Errorf(msg string, args ...interface{}) {
+	w.log(LevelError, fmt.Sprintf(msg, args...))
+}
+
+func (w *wrappedLogger) Fatal(msg string) {
+	w.log(LevelFatal, msg)
+}
+
+func (w *wrappedLogger) Fatalln(args ...interface{}) {
+	w.log(LevelFatal, sprintln(args...))
+}
+
+func (w *wrappedLogger) Fatalf(msg string, args ...interface{}) {
+	w.log(LevelFatal, fmt.Sprintf(msg, args...))
+}
+
+func (w *wrappedLogger) Panic(msg string) {
+	w.log(LevelPanic, msg)
+}
+
+func (w *wrappedLogger) Print(args ...interface{}) {
+	w.log(LevelDebug, fmt.Sprint(args...))
+}
+
+func (w *wrappedLogger) Println(args ...interface{}) {
+	w.log(LevelInfo, sprintln(args...))
+}
+
+func (w *wrappedLogger) Printf(msg string, args ...interface{}) {
+	w.log(LevelInfo, fmt.Sprintf(msg, args...))
+}
+
+func (w *wrappedLogger) WithFields(m map[string]interface{}) Logger {
+	return wrap(w.Logger.WithFields(m), w.handler)
+}
+
+func (w *wrappedLogger) With(fields ...Field) Logger {
+	return wrap(w.Logger.With(fields...), w.handler)
+}
+
+func (w *wrappedLogger) WithPrefix(prefix string) Logger {
+	return wrap(w.Logger.WithPrefix(prefix), w.handler)
+}
+
+func (w *wrappedLogger) WithStruct(v interface{}) Logger {
+	return wrap(w.Logger.WithStruct(v), w.handler)
+}
+
+func (w *wrappedLogger) WithGroup(name string) Logger {
+	return wrap(w.Logger.WithGroup(name), w.handler)
+}
+
+func (w *wrappedLogger) WithContext(ctx context.Context) Logger {
+	return wrap(w.Logger.WithContext(ctx), w.handler)
+}
+
+func (w *wrappedLogger) WithError(err error) Logger {
+	return wrap(w.Logger.WithError(err), w.handler)
+}
+
+func (w *wrappedLogger) WithCode(code string) Logger {
+	return wrap(w.Logger.WithCode(code), w.handler)
+}
+
+// StartTimer starts a timer which logs through the wrapper
+func (w *wrappedLogger) StartTimer(operation string) *Timer {
+	timer := w.Logger.StartTimer(operation)
+	timer.logger = w
+	return timer
+}
+
+// Close closes the handler if it holds resources and then the wrapped logger
+func (w *wrappedLogger) Close() error {
+	if closer, ok := w.handler.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return err
+		}
+	}
+	return w.Logger.Close()
+}
+
+// fieldsOf returns the fields a logger is annotated with, if it exposes them
+func fieldsOf(l Logger) Fields {
+	if f, ok := l.(interface{ fields() Fields }); ok {
+		return f.fields()
+	}
+	return Fields{}
+}
+
+// contextOf returns the context the logger was bound to through WithContext,
+// nil if there is none
+func contextOf(l Logger) context.Context {
+	if c, ok := l.(interface{ entryContext() context.Context }); ok {
+		return c.entryContext()
+	}
+	return nil
+}
+
+// sprintln formats like fmt.Sprintln without the trailing newline
+func sprintln(args ...interface{}) string {
+	msg := fmt.Sprintln(args...)
+	return msg[:len(msg)-1]
+}
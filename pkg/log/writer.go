@@ -0,0 +1,30 @@
+package log
+
+import (
+	"io"
+	"strings"
+)
+
+// logWriter io.Writer which logs every written line
+type logWriter struct {
+	logger Logger
+	level  Level
+}
+
+// Writer returns an io.Writer which logs each line written to it at the given
+// level, e.g. to redirect the standard library log package used by
+// http.Server.ErrorLog:
+//
+//	stdlog.New(log.Writer(logger, log.LevelError), "", 0)
+func Writer(l Logger, level Level) io.Writer {
+	return &logWriter{logger: l, level: level}
+}
+
+// Write logs p line by line, a trailing newline does not produce an empty entry
+func (w *logWriter) Write(p []byte) (int, error) {
+	text := strings.TrimSuffix(string(p), "\n")
+	for _, line := range strings.Split(text, "\n") {
+		logAt(w.logger, w.level, strings.TrimSuffix(line, "\r"))
+	}
+	return len(p), nil
+}
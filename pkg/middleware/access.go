@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// AccessLog logs every request with its method, path, status code, response
+// size, remote address and duration. Successful and redirected requests are
+// logged at info, client errors at warn and server errors at error level.
+// Values of the request context like the request id are added as fields.
+// Requests can be excluded with ExcludePaths and ExcludePathPrefixes.
+func AccessLog(l log.Logger, opts ...AccessLogOption) func(http.Handler) http.Handler {
+	l = excludeFilter(l, "path", opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := NewResponseWriter(w)
+
+			next.ServeHTTP(rw, r)
+
+			status := rw.Status()
+			logger := l.WithContext(r.Context()).WithFields(log.Fields{
+				"method":      r.Method,
+				"path":        r.URL.Path,
+				"status":      status,
+				"size":        log.ByteSize(rw.Size()),
+				"remote_addr": r.RemoteAddr,
+				"duration":    time.Since(start),
+			})
+
+			logByStatus(logger, status, "%s %s", r.Method, r.URL.Path)
+		})
+	}
+}
+
+// DefaultExcludedPaths are the health check and metrics paths usually excluded
+// from access logs
+var DefaultExcludedPaths = []string{"/healthz", "/livez", "/readyz", "/metrics"}
+
+// AccessLogOption configures the access log middlewares
+type AccessLogOption func(*accessLogConfig)
+
+type accessLogConfig struct {
+	paths    map[string]bool
+	prefixes []string
+}
+
+// ExcludePaths excludes requests to exactly the given paths from the access
+// log, they are still served
+func ExcludePaths(paths ...string) AccessLogOption {
+	return func(c *accessLogConfig) {
+		for _, p := range paths {
+			c.paths[p] = true
+		}
+	}
+}
+
+// ExcludePathPrefixes excludes requests to paths starting with one of the
+// given prefixes from the access log, they are still served
+func ExcludePathPrefixes(prefixes ...string) AccessLogOption {
+	return func(c *accessLogConfig) {
+		c.prefixes = append(c.prefixes, prefixes...)
+	}
+}
+
+// excludeFilter returns l filtered to drop the entries whose path, logged
+// under pathKey, is excluded by the options
+func excludeFilter(l log.Logger, pathKey string, opts []AccessLogOption) log.Logger {
+	cfg := &accessLogConfig{paths: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if len(cfg.paths) == 0 && len(cfg.prefixes) == 0 {
+		return l
+	}
+
+	return log.NewFiltered(l, func(_ log.Level, _ string, fields log.Fields) bool {
+		path, _ := fields[pathKey].(string)
+		if cfg.paths[path] {
+			return false
+		}
+		for _, prefix := range cfg.prefixes {
+			if strings.HasPrefix(path, prefix) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// logByStatus logs successful and redirected requests at info, client errors at
+// warn and server errors at error level
+func logByStatus(l log.Logger, status int, format string, args ...interface{}) {
+	switch {
+	case status >= http.StatusInternalServerError:
+		l.Errorf(format, args...)
+	case status >= http.StatusBadRequest:
+		l.Warnf(format, args...)
+	default:
+		l.Infof(format, args...)
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+func TestAccessLog(t *testing.T) {
+	tests := []struct {
+		status int
+		level  log.Level
+	}{
+		{http.StatusOK, log.LevelInfo},
+		{http.StatusFound, log.LevelInfo},
+		{http.StatusNotFound, log.LevelWarn},
+		{http.StatusBadGateway, log.LevelError},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			l, logs := log.NewObserver()
+			h := AccessLog(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte("body"))
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			entries := logs.All()
+			if len(entries) != 1 {
+				t.Fatalf("logged %d entries, want 1", len(entries))
+			}
+			e := entries[0]
+			if e.Level != tt.level {
+				t.Errorf("level = %q, want %q", e.Level, tt.level)
+			}
+			if e.Fields["status"] != tt.status {
+				t.Errorf("status field = %v, want %d", e.Fields["status"], tt.status)
+			}
+			if e.Fields["size"] != log.ByteSize(4) {
+				t.Errorf("size field = %v, want 4", e.Fields["size"])
+			}
+			if e.Fields["path"] != "/api/users" || e.Fields["method"] != http.MethodGet {
+				t.Errorf("method and path fields = %v %v", e.Fields["method"], e.Fields["path"])
+			}
+			if _, ok := e.Fields["duration"].(time.Duration); !ok {
+				t.Errorf("duration field = %#v, want a time.Duration", e.Fields["duration"])
+			}
+		})
+	}
+}
+
+func TestAccessLogImplicitStatus(t *testing.T) {
+	l, logs := log.NewObserver()
+	h := AccessLog(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := logs.FilterField("status", http.StatusOK); len(got) != 1 {
+		t.Errorf("want one entry with status 200, got %v", logs.All())
+	}
+}
+
+func TestAccessLogExcludePaths(t *testing.T) {
+	l, logs := log.NewObserver()
+	served := 0
+	h := AccessLog(l, ExcludePaths(DefaultExcludedPaths...), ExcludePathPrefixes("/debug/"))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			served++
+		}))
+
+	for _, path := range []string{"/healthz", "/metrics", "/debug/pprof/heap", "/api/x", "/api/healthz"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if served != 5 {
+		t.Errorf("served %d requests, want all 5", served)
+	}
+	var paths []interface{}
+	for _, e := range logs.All() {
+		paths = append(paths, e.Fields["path"])
+	}
+	if len(paths) != 2 || paths[0] != "/api/x" || paths[1] != "/api/healthz" {
+		t.Errorf("logged paths %v, want /api/x and /api/healthz", paths)
+	}
+}
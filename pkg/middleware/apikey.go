@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// APIKeyHeader header the API key is read from by default
+const APIKeyHeader = "X-API-Key"
+
+// principalKey is the context key of the authenticated principal
+type principalKey struct{}
+
+// APIKeyOption configures where the APIKey middleware reads the key from
+type APIKeyOption func(*apiKeySource)
+
+type apiKeySource struct {
+	header string
+	query  string
+}
+
+// APIKeyFromHeader reads the key from the header instead of X-API-Key
+func APIKeyFromHeader(name string) APIKeyOption {
+	return func(s *apiKeySource) {
+		s.header = name
+	}
+}
+
+// APIKeyFromQuery reads the key from the query parameter if the header is not
+// set
+func APIKeyFromQuery(name string) APIKeyOption {
+	return func(s *apiKeySource) {
+		s.query = name
+	}
+}
+
+// APIKey authenticates requests by the API key in the X-API-Key header. The
+// validator returns the principal the key belongs to, which is stored in the
+// request context together with a logger annotated with it. Requests without
+// a valid key are answered with 401 and logged at warn level with the client
+// IP, the key itself is never logged.
+func APIKey(l log.Logger, validator func(key string) (principal interface{}, ok bool), opts ...APIKeyOption) func(http.Handler) http.Handler {
+	source := &apiKeySource{header: APIKeyHeader}
+	for _, opt := range opts {
+		opt(source)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(source.header)
+			if key == "" && source.query != "" {
+				key = r.URL.Query().Get(source.query)
+			}
+
+			var principal interface{}
+			ok := false
+			if key != "" {
+				principal, ok = validator(key)
+			}
+			if !ok {
+				reason := "invalid API key"
+				if key == "" {
+					reason = "missing API key"
+				}
+				l.WithContext(r.Context()).WithFields(log.Fields{
+					"method":    r.Method,
+					"path":      r.URL.Path,
+					"client_ip": ClientIP(r),
+				}).Warnf("unauthorized request: %s", reason)
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"error":"unauthorized"}`))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(withPrincipal(r.Context(), l, principal)))
+		})
+	}
+}
+
+// GetPrincipal returns the principal stored in the context by an
+// authentication middleware, nil if the request is not authenticated
+func GetPrincipal(ctx context.Context) interface{} {
+	return ctx.Value(principalKey{})
+}
+
+// withPrincipal returns a copy of ctx carrying the principal and a logger
+// annotated with it and the request id, if there is one
+func withPrincipal(ctx context.Context, l log.Logger, principal interface{}) context.Context {
+	fields := log.Fields{"principal": principal}
+	if id := GetRequestID(ctx); id != "" {
+		fields["request_id"] = id
+	}
+	ctx = context.WithValue(ctx, principalKey{}, principal)
+	return log.NewContext(ctx, l.WithFields(fields))
+}
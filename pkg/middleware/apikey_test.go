@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+func TestAPIKey(t *testing.T) {
+	l, logs := log.NewObserver()
+	validator := func(key string) (interface{}, bool) {
+		return "svc-billing", key == "secret-key"
+	}
+	h := APIKey(l, validator, APIKeyFromQuery("api_key"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := GetPrincipal(r.Context()); got != "svc-billing" {
+			t.Errorf("principal = %v, want svc-billing", got)
+		}
+		log.FromContext(r.Context()).Info("authorized")
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		target string
+		status int
+	}{
+		{"header", "secret-key", "/api/invoices", http.StatusOK},
+		{"query", "", "/api/invoices?api_key=secret-key", http.StatusOK},
+		{"missing", "", "/api/invoices", http.StatusUnauthorized},
+		{"invalid", "wrong-key", "/api/invoices", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		if tt.header != "" {
+			req.Header.Set(APIKeyHeader, tt.header)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.status)
+		}
+	}
+
+	if n := len(logs.FilterField("principal", "svc-billing")); n != 2 {
+		t.Errorf("got %d entries annotated with the principal, want 2", n)
+	}
+	warnings := logs.FilterLevel(log.LevelWarn)
+	if len(warnings) != 2 {
+		t.Fatalf("logged %d warnings, want 2", len(warnings))
+	}
+	if warnings[0].Message != "unauthorized request: missing API key" || warnings[1].Message != "unauthorized request: invalid API key" {
+		t.Errorf("unexpected warnings %q, %q", warnings[0].Message, warnings[1].Message)
+	}
+	for _, e := range logs.All() {
+		for k, v := range e.Fields {
+			if s, ok := v.(string); ok && strings.Contains(s, "wrong-key") {
+				t.Errorf("API key was logged under %s", k)
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// BodyLog logs up to maxBytes of the request and response body of every
+// request at debug level, for debugging integrations. Bodies with binary
+// content types are skipped and the values of the redact keys are masked,
+// log.DefaultRedactedKeys if none are given. It does nothing unless the logger
+// is at debug level.
+func BodyLog(l log.Logger, maxBytes int, redactKeys ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := l.WithContext(r.Context())
+			if !logger.Enabled(log.LevelDebug) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var reqBody *cappedBuffer
+			if r.Body != nil && r.Body != http.NoBody && textual(r.Header.Get("Content-Type")) {
+				reqBody = &cappedBuffer{max: maxBytes}
+				r.Body = &teeBody{ReadCloser: r.Body, buf: reqBody}
+			}
+			bw := &bodyWriter{ResponseWriter: NewResponseWriter(w), buf: &cappedBuffer{max: maxBytes}}
+
+			next.ServeHTTP(bw, r)
+
+			fields := log.Fields{
+				"method": r.Method,
+				"path":   r.URL.Path,
+			}
+			if reqBody != nil {
+				fields["request_body"] = log.RedactBody(reqBody.String(), redactKeys...)
+				fields["request_body_truncated"] = reqBody.truncated
+			}
+			if textual(bw.Header().Get("Content-Type")) {
+				fields["response_body"] = log.RedactBody(bw.buf.String(), redactKeys...)
+				fields["response_body_truncated"] = bw.buf.truncated
+			}
+			logger.WithFields(fields).Debugf("%s %s body", r.Method, r.URL.Path)
+		})
+	}
+}
+
+// textual reports whether a content type is readable text. An empty content
+// type may be anything and doesn't count as text.
+func textual(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/x-www-form-urlencoded":
+		return true
+	}
+	return false
+}
+
+// cappedBuffer keeps the first max bytes written to it
+type cappedBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+// Write keeps as much of b as fits and never fails
+func (c *cappedBuffer) Write(b []byte) (int, error) {
+	if room := c.max - c.Len(); room < len(b) {
+		c.truncated = true
+		if room > 0 {
+			c.Buffer.Write(b[:room])
+		}
+		return len(b), nil
+	}
+	return c.Buffer.Write(b)
+}
+
+// teeBody request body which copies what the handler reads into a buffer
+type teeBody struct {
+	io.ReadCloser
+	buf *cappedBuffer
+}
+
+func (t *teeBody) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	_, _ = t.buf.Write(p[:n])
+	return n, err
+}
+
+// bodyWriter response writer which copies the body into a buffer
+type bodyWriter struct {
+	*ResponseWriter
+	buf *cappedBuffer
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	_, _ = w.buf.Write(b[:n])
+	return n, err
+}
+
+// Unwrap returns the wrapped writer, used by http.ResponseController
+func (w *bodyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
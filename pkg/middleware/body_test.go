@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+func TestBodyLogRedactKeys(t *testing.T) {
+	l, logs := log.NewObserver()
+	h := BodyLog(l, 1024, "pin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"pin":"1234","password":"visible"}`))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/cards", strings.NewReader(`{"pin":"1234"}`))
+	req.Header.Set("Content-Type", "application/json")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := logs.FilterLevel(log.LevelDebug)
+	if len(entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(entries))
+	}
+	reqBody, _ := entries[0].Fields["request_body"].(string)
+	respBody, _ := entries[0].Fields["response_body"].(string)
+	if strings.Contains(reqBody, "1234") || strings.Contains(respBody, "1234") {
+		t.Errorf("pin was logged: %q %q", reqBody, respBody)
+	}
+	if !strings.Contains(respBody, "visible") {
+		t.Errorf("only the given keys should be redacted: %q", respBody)
+	}
+}
+
+func TestBodyLogTruncatesAtCap(t *testing.T) {
+	l, logs := log.NewObserver()
+	h := BodyLog(l, 8)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != "0123456789abcdef" {
+			t.Errorf("handler read %q, want the whole body", body)
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("12345678"))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/echo", strings.NewReader("0123456789abcdef"))
+	req.Header.Set("Content-Type", "text/plain")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := logs.FilterLevel(log.LevelDebug)
+	if len(entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(entries))
+	}
+	fields := entries[0].Fields
+	if fields["request_body"] != "01234567" || fields["request_body_truncated"] != true {
+		t.Errorf("request body over the cap: %q truncated=%v, want %q truncated=true",
+			fields["request_body"], fields["request_body_truncated"], "01234567")
+	}
+	if fields["response_body"] != "12345678" || fields["response_body_truncated"] != false {
+		t.Errorf("response body at the cap: %q truncated=%v, want %q truncated=false",
+			fields["response_body"], fields["response_body_truncated"], "12345678")
+	}
+}
+
+func TestTextual(t *testing.T) {
+	tests := map[string]bool{
+		"":                          false,
+		"application/json":          true,
+		"application/problem+json":  true,
+		"text/plain; charset=utf-8": true,
+		"application/octet-stream":  false,
+		"image/png":                 false,
+		"not a content type;;":      false,
+	}
+	for contentType, want := range tests {
+		if got := textual(contentType); got != want {
+			t.Errorf("textual(%q) = %v, want %v", contentType, got, want)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// compressMinSize is the size below which responses are sent uncompressed
+const compressMinSize = 1024
+
+// Compress compresses responses with gzip or deflate, whichever the client
+// accepts, at the given compression level, e.g. gzip.DefaultCompression.
+// Responses smaller than 1KB, already encoded responses and compressed content
+// types like images are sent as they are. Compressed responses are logged at
+// debug level with the achieved ratio. It panics if level is not a valid
+// compression level.
+func Compress(l log.Logger, level int) func(http.Handler) http.Handler {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		panic(fmt.Sprintf("middleware: invalid compression level %d", level))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			w.Header().Add("Vary", "Accept-Encoding")
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{ResponseWriter: w, encoding: encoding, level: level}
+			defer func() {
+				cw.close()
+				if cw.encoder != nil && cw.written > 0 {
+					l.WithContext(r.Context()).WithFields(log.Fields{
+						"encoding":   encoding,
+						"size":       log.ByteSize(cw.written),
+						"compressed": log.ByteSize(cw.compressed.n),
+						"ratio":      float64(cw.compressed.n) / float64(cw.written),
+					}).Debugf("compressed response of %s %s", r.Method, r.URL.Path)
+				}
+			}()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// newEncoder returns a gzip or deflate writer compressing into w
+func newEncoder(encoding string, w io.Writer, level int) (io.WriteCloser, error) {
+	if encoding == "gzip" {
+		return gzip.NewWriterLevel(w, level)
+	}
+	return flate.NewWriter(w, level)
+}
+
+// negotiateEncoding returns gzip or deflate if the Accept-Encoding header
+// accepts it, gzip preferred, or an empty string if it accepts neither
+func negotiateEncoding(header string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = q > 0
+	}
+
+	switch {
+	case accepted["gzip"]:
+		return "gzip"
+	case accepted["deflate"]:
+		return "deflate"
+	}
+	return ""
+}
+
+// compressWriter buffers the start of a response to decide whether it is worth
+// compressing and compresses it if so
+type compressWriter struct {
+	http.ResponseWriter
+	encoding   string
+	level      int
+	status     int
+	buf        []byte
+	decided    bool
+	encoder    io.WriteCloser
+	compressed countingWriter
+	written    int
+}
+
+// WriteHeader records the status, it is sent once the encoding is decided
+func (w *compressWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	if status < http.StatusOK {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+// Write buffers the body until it is large enough to be compressed
+func (w *compressWriter) Write(b []byte) (int, error) {
+	w.written += len(b)
+	if !w.decided {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < compressMinSize {
+			return len(b), nil
+		}
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends what was written so far, compressed if possible, so streaming
+// responses keep working
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.start(true)
+	}
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer, used by http.ResponseController
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// start sends the header and the buffered body, compressed if compress is set
+// and the response allows it
+func (w *compressWriter) start(compress bool) error {
+	w.decided = true
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	h := w.Header()
+	if compress && compressible(status, h) {
+		w.compressed.w = w.ResponseWriter
+		encoder, err := newEncoder(w.encoding, &w.compressed, w.level)
+		if err != nil {
+			return err
+		}
+		w.encoder = encoder
+	}
+	if w.encoder == nil {
+		w.ResponseWriter.WriteHeader(status)
+		_, err := w.ResponseWriter.Write(w.buf)
+		w.buf = nil
+		return err
+	}
+
+	h.Set("Content-Encoding", w.encoding)
+	h.Del("Content-Length")
+	w.ResponseWriter.WriteHeader(status)
+	_, err := w.encoder.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// close sends a response which was too small to be compressed or finishes the
+// compressed one
+func (w *compressWriter) close() {
+	if !w.decided {
+		if w.status == 0 && len(w.buf) == 0 {
+			return
+		}
+		_ = w.start(false)
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+	}
+}
+
+// compressible reports whether a response with the status and header should be
+// compressed
+func compressible(status int, h http.Header) bool {
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := strings.ToLower(h.Get("Content-Type"))
+	for _, prefix := range []string{"image/", "video/", "audio/", "application/zip", "application/gzip", "application/x-gzip", "application/octet-stream"} {
+		if strings.HasPrefix(contentType, prefix) && !strings.HasPrefix(contentType, "image/svg") {
+			return false
+		}
+	}
+	return true
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += n
+	return n, err
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+func TestCompressInvalidLevel(t *testing.T) {
+	l, _ := log.NewObserver()
+	for _, level := range []int{gzip.HuffmanOnly - 1, gzip.BestCompression + 1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Compress accepted the invalid level %d", level)
+				}
+			}()
+			Compress(l, level)
+		}()
+	}
+}
+
+func TestCompressGzip(t *testing.T) {
+	l, logs := log.NewObserver()
+	body := strings.Repeat("compressible ", 200)
+	h := Compress(l, gzip.DefaultCompression)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(body))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("invalid gzip response: %v", err)
+	}
+	got, err := ioutil.ReadAll(zr)
+	if err != nil || string(got) != body {
+		t.Errorf("decompressed body differs, err %v", err)
+	}
+	if entries := logs.FilterField("encoding", "gzip"); len(entries) != 1 {
+		t.Errorf("want the compression logged once, got %+v", logs.All())
+	}
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// CORSConfig configures the CORS middleware
+type CORSConfig struct {
+	// AllowedOrigins are the origins allowed to make requests, "*" allows any
+	// origin and a "*" inside an origin matches a single host label, e.g.
+	// "https://*.example.com".
+	AllowedOrigins []string
+	// AllowedOriginPatterns are regular expressions matching allowed origins.
+	AllowedOriginPatterns []*regexp.Regexp
+	// AllowedMethods of preflight requests, GET, HEAD and POST if empty.
+	AllowedMethods []string
+	// AllowedHeaders of preflight requests, the requested headers are allowed
+	// if empty.
+	AllowedHeaders []string
+	// ExposedHeaders the browser makes available to scripts.
+	ExposedHeaders []string
+	// AllowCredentials allows cookies and authorization headers. It can't be
+	// combined with the "*" origin, allowed origins must be listed then.
+	AllowCredentials bool
+	// MaxAge browsers may cache the result of a preflight request.
+	MaxAge time.Duration
+}
+
+// CORS answers preflight requests and adds the CORS headers to requests from
+// allowed origins. Requests from other origins are passed on without CORS
+// headers, so the browser blocks them, and logged at debug level. CORS panics
+// if AllowCredentials is combined with the "*" origin, as that would allow
+// credentialed requests from any site.
+func CORS(l log.Logger, cfg CORSConfig) func(http.Handler) http.Handler {
+	if len(cfg.AllowedMethods) == 0 {
+		cfg.AllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	}
+	allowAny := false
+	var patterns []*regexp.Regexp
+	for _, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			if cfg.AllowCredentials {
+				panic(`middleware: CORS origin "*" can't be combined with AllowCredentials`)
+			}
+			allowAny = true
+			continue
+		}
+		if strings.Contains(origin, "*") {
+			pattern := strings.ReplaceAll(regexp.QuoteMeta(origin), `\*`, `[^./]+`)
+			patterns = append(patterns, regexp.MustCompile("^"+pattern+"$"))
+		}
+	}
+	patterns = append(patterns, cfg.AllowedOriginPatterns...)
+
+	allowed := func(origin string) bool {
+		if allowAny {
+			return true
+		}
+		for _, o := range cfg.AllowedOrigins {
+			if strings.EqualFold(o, origin) {
+				return true
+			}
+		}
+		for _, p := range patterns {
+			if p.MatchString(origin) {
+				return true
+			}
+		}
+		return false
+	}
+
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge / time.Second))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			if !allowed(origin) {
+				l.WithContext(r.Context()).WithFields(log.Fields{
+					"origin": origin,
+					"method": r.Method,
+					"path":   r.URL.Path,
+				}).Debugf("rejected CORS request from origin %s", origin)
+				if preflight {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if allowAny {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if cfg.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				if exposed != "" {
+					h.Set("Access-Control-Expose-Headers", exposed)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			h.Set("Access-Control-Allow-Methods", methods)
+			if headers != "" {
+				h.Set("Access-Control-Allow-Headers", headers)
+			} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+				h.Set("Access-Control-Allow-Headers", requested)
+			}
+			if cfg.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", maxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
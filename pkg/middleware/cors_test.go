@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+func TestCORSRejectsWildcardWithCredentials(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("CORS accepted the \"*\" origin combined with AllowCredentials")
+		}
+	}()
+	l, _ := log.NewObserver()
+	CORS(l, CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+}
+
+func TestCORSCredentials(t *testing.T) {
+	l, _ := log.NewObserver()
+	h := CORS(l, CORSConfig{
+		AllowedOrigins:   []string{"https://*.example.com"},
+		AllowCredentials: true,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		origin string
+		want   string
+	}{
+		{"https://app.example.com", "https://app.example.com"},
+		{"https://evil.com", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", tt.origin)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.want {
+			t.Errorf("origin %s: Access-Control-Allow-Origin = %q, want %q", tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestCORSWildcard(t *testing.T) {
+	l, _ := log.NewObserver()
+	h := CORS(l, CORSConfig{AllowedOrigins: []string{"*"}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://any.org")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Access-Control-Allow-Credentials = %q, want none", got)
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	l, logs := log.NewObserver()
+	called := false
+	h := CORS(l, CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPut},
+		ExposedHeaders: []string{"X-Request-Id"},
+		MaxAge:         10 * time.Minute,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/api/users/1", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPut)
+		req.Header.Set("Access-Control-Request-Headers", "Content-Type")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := preflight("https://app.example.com")
+	if rec.Code != http.StatusNoContent || called {
+		t.Errorf("preflight got status %d, handler called %v, want 204 without calling the handler", rec.Code, called)
+	}
+	want := map[string]string{
+		"Access-Control-Allow-Origin":  "https://app.example.com",
+		"Access-Control-Allow-Methods": "GET, PUT",
+		"Access-Control-Allow-Headers": "Content-Type",
+		"Access-Control-Max-Age":       "600",
+	}
+	for k, v := range want {
+		if got := rec.Header().Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+	if got := rec.Header().Get("Access-Control-Expose-Headers"); got != "" {
+		t.Errorf("preflight exposed headers %q", got)
+	}
+
+	rec = preflight("https://evil.com")
+	if rec.Code != http.StatusNoContent || called {
+		t.Errorf("rejected preflight got status %d, handler called %v", rec.Code, called)
+	}
+	for k := range want {
+		if got := rec.Header().Get(k); got != "" {
+			t.Errorf("rejected preflight got %s = %q", k, got)
+		}
+	}
+	if len(logs.FilterField("origin", "https://evil.com")) != 1 {
+		t.Error("rejected origin wasn't logged")
+	}
+}
+
+func TestCORSAllowedAndRejectedOrigin(t *testing.T) {
+	l, _ := log.NewObserver()
+	h := CORS(l, CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		ExposedHeaders: []string{"X-Request-Id"},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	tests := []struct {
+		origin  string
+		allow   string
+		exposed string
+	}{
+		{"https://APP.example.com", "https://APP.example.com", "X-Request-Id"},
+		{"https://evil.com", "", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", tt.origin)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusTeapot {
+			t.Errorf("origin %s: handler wasn't called, got status %d", tt.origin, rec.Code)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.allow {
+			t.Errorf("origin %s: Access-Control-Allow-Origin = %q, want %q", tt.origin, got, tt.allow)
+		}
+		if got := rec.Header().Get("Access-Control-Expose-Headers"); got != tt.exposed {
+			t.Errorf("origin %s: Access-Control-Expose-Headers = %q, want %q", tt.origin, got, tt.exposed)
+		}
+		if got := rec.Header().Get("Vary"); got != "Origin" {
+			t.Errorf("origin %s: Vary = %q, want Origin", tt.origin, got)
+		}
+	}
+}
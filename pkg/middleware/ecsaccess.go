@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+	"github.com/gorilla/mux"
+)
+
+// Field names of ECSAccessLog, following the Elastic Common Schema where it
+// defines one
+const (
+	ECSMethodKey     = "http.request.method"
+	ECSStatusCodeKey = "http.response.status_code"
+	ECSBodyBytesKey  = "http.response.body.bytes"
+	ECSRouteKey      = "http.route"
+	ECSPathKey       = "url.path"
+	ECSClientIPKey   = "client.ip"
+	ECSUserAgentKey  = "user_agent.original"
+	ECSDurationMSKey = "duration_ms"
+	ECSRequestIDKey  = "request_id"
+)
+
+// ECSAccessLog logs every request like AccessLog but with a stable schema of
+// ECS field names, meant for loggers using log.FormatECS or log.FormatJSON
+// whose output is indexed by a log pipeline. The route is the gorilla/mux path
+// template, e.g. "/users/{id}", and is only set if the middleware runs on a
+// router, while the path is always the raw request path. Status code and body
+// bytes are logged as integers and the duration in milliseconds as float.
+// Requests can be excluded with ExcludePaths and ExcludePathPrefixes.
+func ECSAccessLog(l log.Logger, opts ...AccessLogOption) func(http.Handler) http.Handler {
+	l = excludeFilter(l, ECSPathKey, opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := NewResponseWriter(w)
+
+			next.ServeHTTP(rw, r)
+
+			status := rw.Status()
+			fields := log.Fields{
+				ECSMethodKey:     r.Method,
+				ECSStatusCodeKey: status,
+				ECSBodyBytesKey:  rw.Size(),
+				ECSPathKey:       r.URL.Path,
+				ECSClientIPKey:   ClientIP(r),
+				ECSUserAgentKey:  r.UserAgent(),
+				ECSDurationMSKey: float64(time.Since(start)) / float64(time.Millisecond),
+			}
+			if route := mux.CurrentRoute(r); route != nil {
+				if template, err := route.GetPathTemplate(); err == nil {
+					fields[ECSRouteKey] = template
+				}
+			}
+			if id := GetRequestID(r.Context()); id != "" {
+				fields[ECSRequestIDKey] = id
+			}
+
+			logByStatus(l.WithContext(r.Context()).WithFields(fields), status, "%s %s", r.Method, r.URL.Path)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+	"github.com/gorilla/mux"
+)
+
+func TestECSAccessLog(t *testing.T) {
+	l, logs := log.NewObserver()
+	router := mux.NewRouter()
+	router.Use(RequestID(l), ECSAccessLog(l, ExcludePaths("/health")))
+	router.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("not found"))
+	})
+	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.RemoteAddr = "192.0.2.1:54321"
+	req.Header.Set("User-Agent", "curl/8.0")
+	req.Header.Set(RequestIDHeader, "req-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Level != log.LevelWarn {
+		t.Errorf("level = %s, want warn", e.Level)
+	}
+	want := log.Fields{
+		ECSMethodKey:     http.MethodGet,
+		ECSStatusCodeKey: http.StatusNotFound,
+		ECSBodyBytesKey:  9,
+		ECSRouteKey:      "/users/{id}",
+		ECSPathKey:       "/users/42",
+		ECSClientIPKey:   "192.0.2.1",
+		ECSUserAgentKey:  "curl/8.0",
+		ECSRequestIDKey:  "req-1",
+	}
+	for k, v := range want {
+		if e.Fields[k] != v {
+			t.Errorf("%s = %#v, want %#v", k, e.Fields[k], v)
+		}
+	}
+	if _, ok := e.Fields[ECSDurationMSKey].(float64); !ok {
+		t.Errorf("%s = %#v, want a float64", ECSDurationMSKey, e.Fields[ECSDurationMSKey])
+	}
+}
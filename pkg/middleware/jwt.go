@@ -0,0 +1,372 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+	"github.com/bitcubix/golang-rest-api/pkg/response"
+)
+
+// DefaultJWKSCacheTTL is how long keys fetched from a JWKS URL are cached if
+// no other TTL is set
+const DefaultJWKSCacheTTL = time.Hour
+
+const (
+	// jwksMinRefresh is the minimum time between fetches, so tokens with made
+	// up key ids or a failing endpoint can't flood the JWKS endpoint
+	jwksMinRefresh = time.Minute
+	// jwksTimeout limits a fetch with the default client
+	jwksTimeout = 10 * time.Second
+	// jwksMaxBytes is the largest JWKS document read
+	jwksMaxBytes = 1 << 20
+)
+
+// claimsKey is the context key of the claims of a validated token
+type claimsKey struct{}
+
+var (
+	errTokenMissing     = errors.New("missing bearer token")
+	errTokenMalformed   = errors.New("malformed token")
+	errTokenAlgorithm   = errors.New("unsupported signing algorithm")
+	errTokenKey         = errors.New("unknown signing key")
+	errTokenSignature   = errors.New("bad signature")
+	errTokenExpired     = errors.New("token expired")
+	errTokenNoExpiry    = errors.New("token without expiry")
+	errTokenNotYetValid = errors.New("token not valid yet")
+	errTokenIssuer      = errors.New("invalid issuer")
+	errTokenAudience    = errors.New("invalid audience")
+)
+
+// JWTConfig configures the JWT middleware, at least one of Secret, PublicKey
+// and JWKSURL must be set
+type JWTConfig struct {
+	// Secret verifies HS256 signatures.
+	Secret []byte
+	// PublicKey verifies RS256 signatures.
+	PublicKey *rsa.PublicKey
+	// JWKSURL is fetched for the keys verifying RS256 signatures, selected by
+	// the kid header of the token.
+	JWKSURL string
+	// JWKSCacheTTL is how long fetched keys are cached, DefaultJWKSCacheTTL if
+	// zero. The JWKS is fetched at most once a minute, also after failures.
+	JWKSCacheTTL time.Duration
+	// Client fetches the JWKS, a client with a 10s timeout if nil.
+	Client *http.Client
+	// Issuer the iss claim must match if set.
+	Issuer string
+	// Audience the aud claim must contain if set.
+	Audience string
+	// Leeway tolerated when checking exp and nbf against the clock.
+	Leeway time.Duration
+	// AllowMissingExpiry accepts tokens without an exp claim, they are
+	// rejected by default as they would be valid forever.
+	AllowMissingExpiry bool
+}
+
+// Claims are the claims of a validated token
+type Claims map[string]interface{}
+
+// Subject returns the sub claim
+func (c Claims) Subject() string {
+	sub, _ := c["sub"].(string)
+	return sub
+}
+
+// JWT authenticates requests by the bearer token in the Authorization header.
+// The signature, expiry and issuer and audience claims are validated and the
+// claims are stored in the request context, the subject as principal together
+// with a logger annotated with it. Invalid tokens are answered with 401 problem
+// details and the reason is logged at warn level, the token itself is never
+// logged.
+func JWT(l log.Logger, cfg JWTConfig) func(http.Handler) http.Handler {
+	var jwks *jwksCache
+	if cfg.JWKSURL != "" {
+		jwks = newJWKSCache(l, cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := verifyJWT(r, cfg, jwks)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				response.Problem(w, l.WithFields(log.Fields{
+					"client_ip": ClientIP(r),
+					"reason":    err.Error(),
+				}), r, http.StatusUnauthorized, err.Error())
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), claimsKey{}, claims)
+			next.ServeHTTP(w, r.WithContext(withPrincipal(ctx, l, claims.Subject())))
+		})
+	}
+}
+
+// GetClaims returns the claims stored in the context by JWT
+func GetClaims(ctx context.Context) Claims {
+	claims, _ := ctx.Value(claimsKey{}).(Claims)
+	return claims
+}
+
+// verifyJWT returns the claims of the bearer token of the request if it is
+// valid
+func verifyJWT(r *http.Request, cfg JWTConfig, jwks *jwksCache) (Claims, error) {
+	token := r.Header.Get("Authorization")
+	if len(token) < 7 || !strings.EqualFold(token[:7], "bearer ") {
+		return nil, errTokenMissing
+	}
+	token = strings.TrimSpace(token[7:])
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errTokenMalformed
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, errTokenMalformed
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errTokenMalformed
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	switch header.Alg {
+	case "HS256":
+		if len(cfg.Secret) == 0 {
+			return nil, errTokenAlgorithm
+		}
+		mac := hmac.New(sha256.New, cfg.Secret)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return nil, errTokenSignature
+		}
+	case "RS256":
+		key := cfg.PublicKey
+		if jwks != nil && (key == nil || header.Kid != "") {
+			key = jwks.key(r.Context(), header.Kid)
+		}
+		if key == nil {
+			if cfg.PublicKey == nil && jwks == nil {
+				return nil, errTokenAlgorithm
+			}
+			return nil, errTokenKey
+		}
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, errTokenSignature
+		}
+	default:
+		return nil, errTokenAlgorithm
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, errTokenMalformed
+	}
+	return claims, validateClaims(claims, cfg, time.Now())
+}
+
+// validateClaims checks the registered claims of a token with a valid
+// signature
+func validateClaims(claims Claims, cfg JWTConfig, now time.Time) error {
+	exp, ok := claims["exp"].(float64)
+	if !ok && !cfg.AllowMissingExpiry {
+		return errTokenNoExpiry
+	}
+	if ok && now.After(time.Unix(int64(exp), 0).Add(cfg.Leeway)) {
+		return errTokenExpired
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Before(time.Unix(int64(nbf), 0).Add(-cfg.Leeway)) {
+		return errTokenNotYetValid
+	}
+	if cfg.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != cfg.Issuer {
+			return errTokenIssuer
+		}
+	}
+	if cfg.Audience != "" && !hasAudience(claims["aud"], cfg.Audience) {
+		return errTokenAudience
+	}
+	return nil
+}
+
+// hasAudience reports whether the aud claim, a string or a list of strings,
+// contains the audience
+func hasAudience(aud interface{}, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// jwksCache holds the RSA keys of a JWKS URL
+type jwksCache struct {
+	logger log.Logger
+	url    string
+	client *http.Client
+	ttl    time.Duration
+	mu     sync.Mutex
+	keys   map[string]*rsa.PublicKey
+	// fetched is the time of the last successful fetch, attempted that of the
+	// last fetch whether it failed or not
+	fetched   time.Time
+	attempted time.Time
+	// inflight is closed once the running fetch is done, nil if none runs
+	inflight chan struct{}
+}
+
+func newJWKSCache(l log.Logger, cfg JWTConfig) *jwksCache {
+	c := &jwksCache{
+		logger: l,
+		url:    cfg.JWKSURL,
+		client: cfg.Client,
+		ttl:    cfg.JWKSCacheTTL,
+	}
+	if c.client == nil {
+		c.client = &http.Client{Timeout: jwksTimeout}
+	}
+	if c.ttl <= 0 {
+		c.ttl = DefaultJWKSCacheTTL
+	}
+	return c
+}
+
+// key returns the key with the id, fetching the JWKS if the cache expired or
+// doesn't know the id. An empty id matches the only key of the set. Fetches
+// run without holding the lock, concurrent callers needing the same fetch wait
+// for it, and after any fetch the next one waits at least jwksMinRefresh.
+func (c *jwksCache) key(ctx context.Context, kid string) *rsa.PublicKey {
+	c.mu.Lock()
+	for c.inflight != nil {
+		if key, known := c.lookup(kid); known {
+			c.mu.Unlock()
+			return key
+		}
+		inflight := c.inflight
+		c.mu.Unlock()
+		select {
+		case <-inflight:
+		case <-ctx.Done():
+			return nil
+		}
+		c.mu.Lock()
+	}
+
+	key, known := c.lookup(kid)
+	now := time.Now()
+	stale := now.Sub(c.fetched) > c.ttl
+	if (!stale && known) || now.Sub(c.attempted) < jwksMinRefresh {
+		c.mu.Unlock()
+		return key
+	}
+
+	inflight := make(chan struct{})
+	c.inflight, c.attempted = inflight, now
+	c.mu.Unlock()
+
+	// the fetch serves every waiting request, so it must not be canceled with
+	// the request which started it
+	keys, err := c.fetch(context.WithoutCancel(ctx))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.logger.WithContext(ctx).WithError(err).WithFields(log.Fields{"url": c.url}).Error("failed to fetch JWKS")
+	} else {
+		c.keys, c.fetched = keys, time.Now()
+	}
+	c.inflight = nil
+	close(inflight)
+
+	key, _ = c.lookup(kid)
+	return key
+}
+
+func (c *jwksCache) lookup(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key, true
+		}
+	}
+	key, ok := c.keys[kid]
+	return key, ok
+}
+
+// fetch downloads the JWKS and returns its RSA keys by id
+func (c *jwksCache) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, jwksMaxBytes)).Decode(&set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
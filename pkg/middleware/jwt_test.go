@@ -0,0 +1,256 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+var testSecret = []byte("test-secret")
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to encode token segment: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func signHS256(t *testing.T, secret []byte, claims Claims) string {
+	t.Helper()
+
+	signed := encodeSegment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims Claims) string {
+	t.Helper()
+
+	signed := encodeSegment(t, map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// serveJWT sends a request with the bearer token through the JWT middleware
+// and returns the response and the claims the handler received
+func serveJWT(h func(http.Handler) http.Handler, token string) (*httptest.ResponseRecorder, Claims) {
+	var claims Claims
+	handler := h(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims = GetClaims(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec, claims
+}
+
+func TestJWTValid(t *testing.T) {
+	l, _ := log.NewObserver()
+	h := JWT(l, JWTConfig{Secret: testSecret, Issuer: "auth", Audience: "api"})
+
+	token := signHS256(t, testSecret, Claims{
+		"sub": "alice",
+		"iss": "auth",
+		"aud": []string{"web", "api"},
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	rec, claims := serveJWT(h, token)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204: %s", rec.Code, rec.Body.String())
+	}
+	if claims.Subject() != "alice" {
+		t.Errorf("subject = %q, want alice", claims.Subject())
+	}
+}
+
+func TestJWTRejected(t *testing.T) {
+	valid := Claims{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}
+	tests := []struct {
+		name   string
+		token  func(t *testing.T) string
+		reason string
+	}{
+		{"expired", func(t *testing.T) string {
+			return signHS256(t, testSecret, Claims{"sub": "alice", "exp": time.Now().Add(-time.Hour).Unix()})
+		}, "token expired"},
+		{"without expiry", func(t *testing.T) string {
+			return signHS256(t, testSecret, Claims{"sub": "alice"})
+		}, "token without expiry"},
+		{"expiry not a number", func(t *testing.T) string {
+			return signHS256(t, testSecret, Claims{"sub": "alice", "exp": "tomorrow"})
+		}, "token without expiry"},
+		{"wrong signature", func(t *testing.T) string {
+			return signHS256(t, []byte("other-secret"), valid)
+		}, "bad signature"},
+		{"malformed", func(*testing.T) string {
+			return "not-a-token"
+		}, "malformed token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, logs := log.NewObserver()
+			token := tt.token(t)
+			rec, claims := serveJWT(JWT(l, JWTConfig{Secret: testSecret}), token)
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401", rec.Code)
+			}
+			if claims != nil {
+				t.Errorf("handler was called with claims %v", claims)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/problem+json" {
+				t.Errorf("Content-Type = %q, want application/problem+json", got)
+			}
+			if got := rec.Header().Get("WWW-Authenticate"); !strings.HasPrefix(got, "Bearer") {
+				t.Errorf("WWW-Authenticate = %q", got)
+			}
+
+			warnings := logs.FilterField("reason", tt.reason)
+			if len(warnings) != 1 || warnings[0].Level != log.LevelWarn {
+				t.Fatalf("want one warning with reason %q, got %+v", tt.reason, logs.All())
+			}
+			if strings.Contains(fmt.Sprint(warnings[0]), token) {
+				t.Errorf("the token was logged: %+v", warnings[0])
+			}
+		})
+	}
+}
+
+func TestJWTAllowMissingExpiry(t *testing.T) {
+	l, _ := log.NewObserver()
+	h := JWT(l, JWTConfig{Secret: testSecret, AllowMissingExpiry: true})
+
+	rec, claims := serveJWT(h, signHS256(t, testSecret, Claims{"sub": "alice"}))
+	if rec.Code != http.StatusNoContent || claims.Subject() != "alice" {
+		t.Errorf("status = %d, claims = %v, want the token without expiry accepted", rec.Code, claims)
+	}
+	rec, _ = serveJWT(h, signHS256(t, testSecret, Claims{"sub": "alice", "exp": time.Now().Add(-time.Hour).Unix()}))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expired token got %d, want 401", rec.Code)
+	}
+}
+
+// jwksServer serves the public key as JWKS and counts the requests
+type jwksServer struct {
+	key     *rsa.PublicKey
+	kid     string
+	fetches int32
+	failing int32
+	delay   time.Duration
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&s.fetches, 1)
+	time.Sleep(s.delay)
+	if atomic.LoadInt32(&s.failing) != 0 {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": s.kid,
+			"n":   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+		}},
+	})
+}
+
+func TestJWTJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	jwks := &jwksServer{key: &key.PublicKey, kid: "k1", delay: 50 * time.Millisecond}
+	srv := httptest.NewServer(jwks)
+	defer srv.Close()
+
+	l, _ := log.NewObserver()
+	h := JWT(l, JWTConfig{JWKSURL: srv.URL})
+	token := signRS256(t, key, "k1", Claims{"sub": "bob", "exp": time.Now().Add(time.Hour).Unix()})
+
+	// concurrent requests share one fetch
+	var wg sync.WaitGroup
+	codes := make([]int, 10)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rec, _ := serveJWT(h, token)
+			codes[i] = rec.Code
+		}(i)
+	}
+	wg.Wait()
+	for i, code := range codes {
+		if code != http.StatusNoContent {
+			t.Errorf("request %d got %d, want 204", i, code)
+		}
+	}
+	if n := atomic.LoadInt32(&jwks.fetches); n != 1 {
+		t.Errorf("fetched the JWKS %d times, want 1", n)
+	}
+
+	// unknown key ids don't refetch within jwksMinRefresh
+	other := signRS256(t, key, "unknown", Claims{"sub": "bob"})
+	for i := 0; i < 3; i++ {
+		if rec, _ := serveJWT(h, other); rec.Code != http.StatusUnauthorized {
+			t.Errorf("token with unknown kid got %d, want 401", rec.Code)
+		}
+	}
+	if n := atomic.LoadInt32(&jwks.fetches); n != 1 {
+		t.Errorf("fetched the JWKS %d times, want 1", n)
+	}
+}
+
+func TestJWTJWKSFailureBackoff(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	jwks := &jwksServer{key: &key.PublicKey, kid: "k1", failing: 1}
+	srv := httptest.NewServer(jwks)
+	defer srv.Close()
+
+	l, logs := log.NewObserver()
+	h := JWT(l, JWTConfig{JWKSURL: srv.URL})
+	token := signRS256(t, key, "k1", Claims{"sub": "bob"})
+
+	for i := 0; i < 5; i++ {
+		if rec, _ := serveJWT(h, token); rec.Code != http.StatusUnauthorized {
+			t.Errorf("request %d got %d, want 401", i, rec.Code)
+		}
+	}
+	if n := atomic.LoadInt32(&jwks.fetches); n != 1 {
+		t.Errorf("fetched the failing JWKS %d times, want 1 within jwksMinRefresh", n)
+	}
+	if errs := logs.FilterLevel(log.LevelError); len(errs) != 1 {
+		t.Errorf("logged %d fetch errors, want 1", len(errs))
+	}
+}
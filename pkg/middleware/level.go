@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// WithLevel makes loggers taken from the request context through
+// log.FromContext, or bound to it through WithContext, log at the given level
+// for the wrapped routes, e.g. to debug a single endpoint without raising the
+// level of the whole service
+func WithLevel(level log.Level) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(log.ContextWithLevel(r.Context(), level)))
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+func TestWithLevel(t *testing.T) {
+	l, logs := log.NewObserver()
+	l.SetLevel(log.LevelInfo)
+
+	withLogger := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(log.NewContext(r.Context(), l)))
+		})
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.FromContext(r.Context()).WithFields(log.Fields{"path": r.URL.Path}).Debug("handling request")
+	})
+	debugged := withLogger(WithLevel(log.LevelDebug)(handler))
+	plain := withLogger(handler)
+
+	debugged.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/debugged", nil))
+	plain.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/plain", nil))
+	l.Debug("outside of requests")
+
+	entries := logs.FilterLevel(log.LevelDebug)
+	if len(entries) != 1 || entries[0].Fields["path"] != "/debugged" {
+		t.Errorf("got debug entries %+v, want only the one of /debugged", entries)
+	}
+	if l.Enabled(log.LevelDebug) {
+		t.Errorf("the level override changed the level of the logger")
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// MaxBodyBytes limits request bodies to n bytes. Requests announcing a larger
+// Content-Length are answered with 413 right away, larger bodies without one
+// fail to read with *http.MaxBytesError, which request.Bind turns into a 413
+// error. If the handler writes no response after reading too much 413 is
+// written for it. Exceeding the limit is logged at warn level with the remote
+// address.
+func MaxBodyBytes(l log.Logger, n int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logExceeded := func() {
+				l.WithContext(r.Context()).WithFields(log.Fields{
+					"method":         r.Method,
+					"path":           r.URL.Path,
+					"remote_addr":    r.RemoteAddr,
+					"content_length": r.ContentLength,
+					"limit":          log.ByteSize(n),
+				}).Warn("request body too large")
+			}
+
+			if r.ContentLength > n {
+				logExceeded()
+				writeTooLarge(w)
+				return
+			}
+			if r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body := &limitedBody{ReadCloser: http.MaxBytesReader(w, r.Body, n), onExceed: logExceeded}
+			r.Body = body
+			rw := NewResponseWriter(w)
+
+			next.ServeHTTP(rw, r)
+
+			if body.exceeded && !rw.Written() {
+				writeTooLarge(rw)
+			}
+		})
+	}
+}
+
+func writeTooLarge(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	_, _ = w.Write([]byte(`{"error":"request body too large"}`))
+}
+
+// limitedBody reports the first read failing because the body is too large
+type limitedBody struct {
+	io.ReadCloser
+	once     sync.Once
+	onExceed func()
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxErr *http.MaxBytesError
+	if err != nil && errors.As(err, &maxErr) {
+		b.once.Do(func() {
+			b.exceeded = true
+			b.onExceed()
+		})
+	}
+	return n, err
+}
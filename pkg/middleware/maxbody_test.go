@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+func TestMaxBodyBytes(t *testing.T) {
+	l, logs := log.NewObserver()
+	called := 0
+	h := MaxBodyBytes(l, 8)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called++
+		body, err := ioutil.ReadAll(r.Body)
+		var maxErr *http.MaxBytesError
+		if err != nil && !errors.As(err, &maxErr) {
+			t.Errorf("reading the body failed with %v, want *http.MaxBytesError", err)
+		}
+		if err == nil {
+			_, _ = w.Write(body)
+		}
+	}))
+
+	tests := []struct {
+		name          string
+		body          string
+		contentLength int64
+		status        int
+		called        int
+	}{
+		{"within limit", "12345678", 8, http.StatusOK, 1},
+		{"announced too large", "123456789", 9, http.StatusRequestEntityTooLarge, 0},
+		{"chunked too large", "123456789", -1, http.StatusRequestEntityTooLarge, 1},
+	}
+	for _, tt := range tests {
+		called = 0
+		logs.Reset()
+		req := httptest.NewRequest(http.MethodPost, "/api/upload", strings.NewReader(tt.body))
+		req.ContentLength = tt.contentLength
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.status)
+		}
+		if called != tt.called {
+			t.Errorf("%s: handler called %d times, want %d", tt.name, called, tt.called)
+		}
+		warnings := logs.FilterLevel(log.LevelWarn)
+		if tt.status == http.StatusOK {
+			if rec.Body.String() != tt.body || len(warnings) != 0 {
+				t.Errorf("%s: got body %q and warnings %+v", tt.name, rec.Body.String(), warnings)
+			}
+			continue
+		}
+		if rec.Body.String() != `{"error":"request body too large"}` {
+			t.Errorf("%s: body = %q", tt.name, rec.Body.String())
+		}
+		if len(warnings) != 1 || warnings[0].Fields["limit"] != log.ByteSize(8) {
+			t.Errorf("%s: got warnings %+v, want one with the limit", tt.name, warnings)
+		}
+	}
+}
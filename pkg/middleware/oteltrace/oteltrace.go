@@ -0,0 +1,113 @@
+// Package oteltrace traces http requests with OpenTelemetry. It is kept apart
+// from the middleware package so only users of OpenTelemetry depend on it.
+package oteltrace
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+	"github.com/bitcubix/golang-rest-api/pkg/log/otellog"
+	"github.com/bitcubix/golang-rest-api/pkg/middleware"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation name of the spans
+const tracerName = "github.com/bitcubix/golang-rest-api/pkg/middleware/oteltrace"
+
+// Option configures the Trace middleware
+type Option func(*config)
+
+type config struct {
+	provider    trace.TracerProvider
+	propagators propagation.TextMapPropagator
+}
+
+// WithTracerProvider sets the provider spans are created with instead of the
+// global one
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = provider
+	}
+}
+
+// WithPropagators sets the propagators the parent span is extracted from the
+// request headers with instead of the global ones
+func WithPropagators(propagators propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = propagators
+	}
+}
+
+// Trace starts a server span for every request, continuing the trace of the
+// request headers. The span is named by the gorilla/mux route template if the
+// middleware runs on a router and by the method otherwise, and records the
+// status code, 5xx responses and panics as errors. The span is in the request
+// context, so loggers configured with otellog.WithTraceCorrelation add its ids,
+// and a logger annotated with them is stored for log.FromContext.
+func Trace(l log.Logger, opts ...Option) func(http.Handler) http.Handler {
+	cfg := &config{
+		provider:    otel.GetTracerProvider(),
+		propagators: otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	tracer := cfg.provider.Tracer(tracerName)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := cfg.propagators.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			name := r.Method
+			attrs := []attribute.KeyValue{
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+				semconv.ServerAddress(r.Host),
+				semconv.UserAgentOriginal(r.UserAgent()),
+				semconv.ClientAddress(middleware.ClientIP(r)),
+			}
+			if route := mux.CurrentRoute(r); route != nil {
+				if template, err := route.GetPathTemplate(); err == nil {
+					name += " " + template
+					attrs = append(attrs, semconv.HTTPRoute(template))
+				}
+			}
+
+			ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+			defer span.End()
+
+			fields := otellog.TraceFields(ctx)
+			if fields == nil {
+				fields = log.Fields{}
+			}
+			if id := middleware.GetRequestID(ctx); id != "" {
+				fields["request_id"] = id
+			}
+			ctx = log.NewContext(ctx, l.WithFields(fields))
+
+			rw := middleware.NewResponseWriter(w)
+			defer func() {
+				if rec := recover(); rec != nil {
+					span.RecordError(fmt.Errorf("panic: %v", rec))
+					span.SetStatus(codes.Error, "panic")
+					panic(rec)
+				}
+
+				status := rw.Status()
+				span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+				if status >= http.StatusInternalServerError {
+					span.SetStatus(codes.Error, http.StatusText(status))
+				}
+			}()
+
+			next.ServeHTTP(rw, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package oteltrace
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newRecorder() (*tracetest.SpanRecorder, Option) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return recorder, WithTracerProvider(provider)
+}
+
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTraceSpanPerRequest(t *testing.T) {
+	recorder, opt := newRecorder()
+	l, logs := log.NewObserver()
+
+	router := mux.NewRouter()
+	router.Use(Trace(l, opt))
+	router.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		log.FromContext(r.Context()).Info("loading user")
+		w.WriteHeader(http.StatusOK)
+	})
+	router.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	for _, path := range []string{"/users/1", "/users/2", "/fail"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want one per request", len(spans))
+	}
+	for i, span := range spans[:2] {
+		if span.Name() != "GET /users/{id}" {
+			t.Errorf("span %d name = %q, want the route template", i, span.Name())
+		}
+		if span.SpanKind() != trace.SpanKindServer {
+			t.Errorf("span %d kind = %v, want server", i, span.SpanKind())
+		}
+		if got := attr(span, semconv.HTTPResponseStatusCodeKey).AsInt64(); got != http.StatusOK {
+			t.Errorf("span %d status code = %d, want 200", i, got)
+		}
+	}
+	if spans[0].SpanContext().TraceID() == spans[1].SpanContext().TraceID() {
+		t.Errorf("requests share a trace")
+	}
+	if got := spans[2].Status().Code; got != codes.Error {
+		t.Errorf("status of the failed request = %v, want error", got)
+	}
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("logged %d entries, want 2", len(entries))
+	}
+	for i, e := range entries {
+		if e.Fields["trace_id"] != spans[i].SpanContext().TraceID().String() {
+			t.Errorf("entry %d trace_id = %v, want the id of its span", i, e.Fields["trace_id"])
+		}
+	}
+}
+
+func TestTraceRecordsPanic(t *testing.T) {
+	recorder, opt := newRecorder()
+	l, _ := log.NewObserver()
+	h := Trace(l, opt)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	func() {
+		defer func() {
+			if rec := recover(); rec != "boom" {
+				t.Errorf("recovered %v, want the panic to be passed on", rec)
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil))
+	}()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if spans[0].Name() != http.MethodPost {
+		t.Errorf("span name = %q, want the method without router", spans[0].Name())
+	}
+	if spans[0].Status().Code != codes.Error || len(spans[0].Events()) == 0 {
+		t.Errorf("panic wasn't recorded: status %v, events %v", spans[0].Status(), spans[0].Events())
+	}
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// rateLimitSweepInterval is how often idle buckets are removed
+const rateLimitSweepInterval = time.Minute
+
+// RateLimit limits every client, keyed by its IP address, to rps requests per
+// second with bursts of up to burst requests. Requests over the limit are
+// answered with 429 and logged at warn level.
+func RateLimit(l log.Logger, rps float64, burst int) func(http.Handler) http.Handler {
+	return RateLimitByKey(l, rps, burst, ClientIP)
+}
+
+// RateLimitByKey works like RateLimit but keys the clients by the given func,
+// e.g. by API key
+func RateLimitByKey(l log.Logger, rps float64, burst int, key func(r *http.Request) string) func(http.Handler) http.Handler {
+	limiter := &rateLimiter{
+		rate:    rps,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client := key(r)
+			if ok, retry := limiter.allow(client, time.Now()); !ok {
+				l.WithContext(r.Context()).WithFields(log.Fields{
+					"client": client,
+					"rate":   rps,
+					"burst":  burst,
+					"method": r.Method,
+					"path":   r.URL.Path,
+				}).Warnf("rate limit exceeded by %s", client)
+
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = w.Write([]byte(`{"error":"too many requests"}`))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ClientIP returns the IP address of the client which sent the request
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimiter holds a token bucket per client
+type rateLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// tokenBucket holds the tokens of a client at the time it was last used
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// allow takes a token of the client and reports whether there was one, and if
+// not how long until the next one is available
+func (rl *rateLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if now.Sub(rl.lastSweep) >= rateLimitSweepInterval {
+		rl.sweep(now)
+	}
+
+	bucket, ok := rl.buckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: rl.burst, last: now}
+		rl.buckets[client] = bucket
+	}
+
+	bucket.tokens = math.Min(rl.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*rl.rate)
+	bucket.last = now
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	if rl.rate <= 0 {
+		return false, rateLimitSweepInterval
+	}
+	return false, time.Duration((1 - bucket.tokens) / rl.rate * float64(time.Second))
+}
+
+// sweep removes the buckets which were refilled completely, they are in the
+// same state as new ones
+func (rl *rateLimiter) sweep(now time.Time) {
+	rl.lastSweep = now
+	for client, bucket := range rl.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*rl.rate >= rl.burst {
+			delete(rl.buckets, client)
+		}
+	}
+}
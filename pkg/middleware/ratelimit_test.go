@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+func TestRateLimit(t *testing.T) {
+	l, logs := log.NewObserver()
+	h := RateLimit(l, 1, 3)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 3; i++ {
+		if rec := serve("10.0.0.1:1234"); rec.Code != http.StatusNoContent {
+			t.Fatalf("request %d of the burst got %d, want 204", i+1, rec.Code)
+		}
+	}
+
+	rec := serve("10.0.0.1:5678")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("request after the burst got %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+
+	if rec := serve("10.0.0.2:1234"); rec.Code != http.StatusNoContent {
+		t.Errorf("other client got %d, want 204", rec.Code)
+	}
+
+	warnings := logs.FilterLevel(log.LevelWarn)
+	if len(warnings) != 1 {
+		t.Fatalf("logged %d warnings, want 1", len(warnings))
+	}
+	if warnings[0].Fields["client"] != "10.0.0.1" || warnings[0].Fields["rate"] != 1.0 {
+		t.Errorf("warning fields = %v", warnings[0].Fields)
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	rl := &rateLimiter{rate: 2, burst: 2, buckets: make(map[string]*tokenBucket)}
+	now := time.Now()
+
+	rl.allow("a", now)
+	rl.allow("a", now)
+	if ok, retry := rl.allow("a", now); ok || retry != 500*time.Millisecond {
+		t.Errorf("allow with empty bucket = %v, %v, want false, 500ms", ok, retry)
+	}
+	if ok, _ := rl.allow("a", now.Add(500*time.Millisecond)); !ok {
+		t.Errorf("allow after refill of one token = false, want true")
+	}
+
+	rl.sweep(now.Add(time.Hour))
+	if len(rl.buckets) != 0 {
+		t.Errorf("sweep kept %d idle buckets, want 0", len(rl.buckets))
+	}
+}
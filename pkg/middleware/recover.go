@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// Recover recovers panics of the handler, logs them at error level with the
+// stack trace and responds with 500. http.ErrAbortHandler is panicked again so
+// the server can abort the response as intended.
+func Recover(l log.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := NewResponseWriter(w)
+
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				l.WithContext(r.Context()).WithFields(log.Fields{
+					"panic":  rec,
+					"method": r.Method,
+					"path":   r.URL.Path,
+					"stack":  string(debug.Stack()),
+				}).Errorf("recovered from panic: %v", rec)
+
+				if !rw.Written() {
+					rw.Header().Set("Content-Type", "application/json")
+					rw.WriteHeader(http.StatusInternalServerError)
+					_, _ = rw.Write([]byte(`{"error":"internal server error"}`))
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+const (
+	// RequestIDHeader header the request id is read from and written to
+	RequestIDHeader = "X-Request-ID"
+	// maxRequestIDLength is the longest request id accepted from a client
+	maxRequestIDLength = 128
+)
+
+// RequestID reads the request id from the X-Request-ID header, generating a
+// UUID if there is none or it isn't a valid id, and sets it on the response.
+// The id is stored in the request context under log.RequestIDKey together with
+// a logger annotated with it, so handlers get request scoped logging through
+// log.FromContext.
+func RequestID(l log.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = newUUID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+
+			ctx := context.WithValue(r.Context(), log.RequestIDKey, id)
+			ctx = log.NewContext(ctx, l.WithFields(log.Fields{"request_id": id}))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// GetRequestID returns the request id stored in the context by RequestID
+func GetRequestID(ctx context.Context) string {
+	return log.RequestIDFromContext(ctx)
+}
+
+// validRequestID reports whether the id sent by a client may be logged and
+// echoed, which are ids of up to maxRequestIDLength letters, digits and
+// '-', '_', '.', ':' or '/'
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '/':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newUUID returns a random version 4 UUID
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
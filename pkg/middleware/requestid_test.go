@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestRequestIDOnEveryLogLine(t *testing.T) {
+	l, logs := log.NewObserver()
+	h := RequestID(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := log.FromContext(r.Context())
+		logger.Info("loading user")
+		logger.WithPrefix("db").Warn("slow query")
+		logger.Error("user not found")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(RequestIDHeader); got != "req-42" {
+		t.Errorf("response header = %q, want the id of the request", got)
+	}
+	if logs.Len() != 3 || len(logs.FilterField("request_id", "req-42")) != 3 {
+		t.Errorf("want the request id on all 3 entries, got %v", logs.All())
+	}
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{"missing", ""},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1)},
+		{"line break", "abc\nlevel=error msg=forged"},
+		{"quote", `abc" injected="1`},
+		{"space", "abc def"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var id string
+			h := RequestID(log.NewNoop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				id = GetRequestID(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(RequestIDHeader, tt.id)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if !uuidPattern.MatchString(id) {
+				t.Errorf("request id = %q, want a generated UUID", id)
+			}
+			if got := rec.Header().Get(RequestIDHeader); got != id {
+				t.Errorf("response header = %q, want %q", got, id)
+			}
+		})
+	}
+}
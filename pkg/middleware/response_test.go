@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseWriterHijackNotSupported(t *testing.T) {
+	rw := NewResponseWriter(httptest.NewRecorder())
+
+	if _, _, err := rw.Hijack(); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("Hijack error = %v, want http.ErrNotSupported", err)
+	}
+	if rw.Written() {
+		t.Errorf("failed Hijack marked the response as written")
+	}
+}
+
+func TestResponseWriterHijack(t *testing.T) {
+	status := make(chan int, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := NewResponseWriter(w)
+		conn, buf, err := rw.Hijack()
+		if err != nil {
+			t.Errorf("Hijack failed: %v", err)
+			status <- 0
+			return
+		}
+		defer conn.Close()
+		_, _ = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\n")
+		_ = buf.Flush()
+		status <- rw.Status()
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "test")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("client got status %d, want 101", resp.StatusCode)
+	}
+	if got := <-status; got != http.StatusSwitchingProtocols {
+		t.Errorf("recorded status %d, want 101", got)
+	}
+}
+
+var _ http.Hijacker = (*ResponseWriter)(nil)
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// SecureHeadersConfig configures the SecureHeaders middleware, an empty value
+// disables the header
+type SecureHeadersConfig struct {
+	// ContentTypeOptions is sent as X-Content-Type-Options.
+	ContentTypeOptions string
+	// FrameOptions is sent as X-Frame-Options.
+	FrameOptions string
+	// HSTSMaxAge is sent as max-age of Strict-Transport-Security.
+	HSTSMaxAge time.Duration
+	// HSTSIncludeSubdomains adds includeSubDomains to Strict-Transport-Security.
+	HSTSIncludeSubdomains bool
+	// ReferrerPolicy is sent as Referrer-Policy.
+	ReferrerPolicy string
+	// ContentSecurityPolicy is sent as Content-Security-Policy.
+	ContentSecurityPolicy string
+}
+
+// DefaultSecureHeadersConfig returns a config suitable for a JSON API served
+// over TLS
+func DefaultSecureHeadersConfig() SecureHeadersConfig {
+	return SecureHeadersConfig{
+		ContentTypeOptions:    "nosniff",
+		FrameOptions:          "DENY",
+		HSTSMaxAge:            365 * 24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+		ReferrerPolicy:        "no-referrer",
+		ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+	}
+}
+
+// SecureHeaders sets the security headers of the config on every response. A
+// warning is logged once if HSTS is enabled but a request arrives without TLS,
+// as browsers ignore the header then.
+func SecureHeaders(l log.Logger, cfg SecureHeadersConfig) func(http.Handler) http.Handler {
+	headers := make(map[string]string)
+	if cfg.ContentTypeOptions != "" {
+		headers["X-Content-Type-Options"] = cfg.ContentTypeOptions
+	}
+	if cfg.FrameOptions != "" {
+		headers["X-Frame-Options"] = cfg.FrameOptions
+	}
+	if cfg.HSTSMaxAge > 0 {
+		hsts := "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge/time.Second))
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		headers["Strict-Transport-Security"] = hsts
+	}
+	if cfg.ReferrerPolicy != "" {
+		headers["Referrer-Policy"] = cfg.ReferrerPolicy
+	}
+	if cfg.ContentSecurityPolicy != "" {
+		headers["Content-Security-Policy"] = cfg.ContentSecurityPolicy
+	}
+
+	var warnOnce sync.Once
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cfg.HSTSMaxAge > 0 && r.TLS == nil && r.Header.Get("X-Forwarded-Proto") != "https" {
+				warnOnce.Do(func() {
+					l.WithContext(r.Context()).Warn("HSTS is enabled but requests are served without TLS, browsers ignore Strict-Transport-Security")
+				})
+			}
+
+			h := w.Header()
+			for k, v := range headers {
+				h.Set(k, v)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
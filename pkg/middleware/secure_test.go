@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+func TestSecureHeaders(t *testing.T) {
+	l, logs := log.NewObserver()
+	h := SecureHeaders(l, DefaultSecureHeadersConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.TLS = &tls.ConnectionState{}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	want := map[string]string{
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           "DENY",
+		"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+		"Referrer-Policy":           "no-referrer",
+		"Content-Security-Policy":   "default-src 'none'; frame-ancestors 'none'",
+	}
+	for k, v := range want {
+		if got := rec.Header().Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+	if logs.Len() != 0 {
+		t.Errorf("request over TLS was logged: %+v", logs.All())
+	}
+}
+
+func TestSecureHeadersDisabled(t *testing.T) {
+	l, _ := log.NewObserver()
+	h := SecureHeaders(l, SecureHeadersConfig{FrameOptions: "SAMEORIGIN"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := rec.Header().Get("X-Frame-Options"); got != "SAMEORIGIN" {
+		t.Errorf("X-Frame-Options = %q, want SAMEORIGIN", got)
+	}
+	for _, k := range []string{"X-Content-Type-Options", "Strict-Transport-Security", "Referrer-Policy", "Content-Security-Policy"} {
+		if got := rec.Header().Get(k); got != "" {
+			t.Errorf("disabled header %s = %q", k, got)
+		}
+	}
+}
+
+func TestSecureHeadersWarnsOnceWithoutTLS(t *testing.T) {
+	l, logs := log.NewObserver()
+	h := SecureHeaders(l, DefaultSecureHeadersConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	proxied := httptest.NewRequest(http.MethodGet, "/", nil)
+	proxied.Header.Set("X-Forwarded-Proto", "https")
+	h.ServeHTTP(httptest.NewRecorder(), proxied)
+	if logs.Len() != 0 {
+		t.Errorf("request forwarded over https was logged: %+v", logs.All())
+	}
+
+	for i := 0; i < 3; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	if n := len(logs.FilterLevel(log.LevelWarn)); n != 1 {
+		t.Errorf("logged %d warnings, want 1", n)
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// SlowRequestLog logs requests taking longer than a threshold which can be
+// changed at runtime
+type SlowRequestLog struct {
+	logger    log.Logger
+	threshold int64
+}
+
+// NewSlowRequestLog returns a SlowRequestLog for the given threshold, its
+// Handler method is the middleware
+func NewSlowRequestLog(l log.Logger, threshold time.Duration) *SlowRequestLog {
+	return &SlowRequestLog{logger: l, threshold: int64(threshold)}
+}
+
+// SlowRequest logs requests taking longer than the threshold at warn level
+// with their method, path and duration, so latency outliers stand out while
+// the access log stays quiet. Use NewSlowRequestLog to change the threshold at
+// runtime.
+func SlowRequest(l log.Logger, threshold time.Duration) func(http.Handler) http.Handler {
+	return NewSlowRequestLog(l, threshold).Handler
+}
+
+// Threshold returns the duration above which requests are logged
+func (s *SlowRequestLog) Threshold() time.Duration {
+	return time.Duration(atomic.LoadInt64(&s.threshold))
+}
+
+// SetThreshold sets the duration above which requests are logged
+func (s *SlowRequestLog) SetThreshold(threshold time.Duration) {
+	atomic.StoreInt64(&s.threshold, int64(threshold))
+}
+
+// Handler returns the middleware wrapping next
+func (s *SlowRequestLog) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		next.ServeHTTP(w, r)
+
+		duration := time.Since(start)
+		threshold := s.Threshold()
+		if duration <= threshold {
+			return
+		}
+
+		s.logger.WithContext(r.Context()).WithFields(log.Fields{
+			"method":    r.Method,
+			"path":      r.URL.Path,
+			"duration":  duration,
+			"threshold": threshold,
+		}).Warnf("slow request %s %s", r.Method, r.URL.Path)
+	})
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+func TestSlowRequest(t *testing.T) {
+	l, logs := log.NewObserver()
+	slow := NewSlowRequestLog(l, 20*time.Millisecond)
+	h := slow.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fast", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d entries, want only the slow request", len(entries))
+	}
+	e := entries[0]
+	if e.Level != log.LevelWarn || e.Fields["path"] != "/slow" || e.Fields["threshold"] != 20*time.Millisecond {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if d, _ := e.Fields["duration"].(time.Duration); d < 50*time.Millisecond {
+		t.Errorf("duration = %v, want at least 50ms", d)
+	}
+
+	logs.Reset()
+	slow.SetThreshold(time.Second)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if logs.Len() != 0 {
+		t.Errorf("request below the raised threshold was logged: %+v", logs.All())
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+func TestTimeoutInTime(t *testing.T) {
+	l, logs := log.NewObserver()
+	h := Timeout(l, time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Handler", "done")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("created"))
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/users", nil))
+
+	if rec.Code != http.StatusCreated || rec.Body.String() != "created" || rec.Header().Get("X-Handler") != "done" {
+		t.Errorf("got %d %q %v, want the response of the handler", rec.Code, rec.Body.String(), rec.Header())
+	}
+	if logs.Len() != 0 {
+		t.Errorf("request in time was logged: %+v", logs.All())
+	}
+}
+
+func TestTimeoutExceeded(t *testing.T) {
+	l, logs := log.NewObserver()
+	lateWrite := make(chan error, 1)
+	h := Timeout(l, 20*time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		time.Sleep(10 * time.Millisecond)
+		_, err := w.Write([]byte("too late"))
+		lateWrite <- err
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/report", nil))
+
+	if rec.Code != http.StatusGatewayTimeout || rec.Body.String() != `{"error":"request timed out"}` {
+		t.Errorf("got %d %q, want 504 with the timeout error", rec.Code, rec.Body.String())
+	}
+	if err := <-lateWrite; err != http.ErrHandlerTimeout {
+		t.Errorf("late write returned %v, want http.ErrHandlerTimeout", err)
+	}
+	entries := logs.FilterLevel(log.LevelWarn)
+	if len(entries) != 1 || entries[0].Fields["path"] != "/api/report" {
+		t.Errorf("got warn entries %+v, want one for /api/report", entries)
+	}
+}
+
+func TestTimeoutCanceled(t *testing.T) {
+	l, _ := log.NewObserver()
+	release := make(chan struct{})
+	defer close(release)
+	h := Timeout(l, time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503 for a canceled request", rec.Code)
+	}
+}
+
+func TestTimeoutPanicPropagates(t *testing.T) {
+	l, _ := log.NewObserver()
+	h := Timeout(l, time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	defer func() {
+		if rec := recover(); rec != "boom" {
+			t.Errorf("recovered %v, want the panic of the handler", rec)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,149 @@
+// Package pagination parses the pagination parameters of list endpoints and
+// builds the envelope their responses are wrapped in
+package pagination
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+	"github.com/bitcubix/golang-rest-api/pkg/response"
+)
+
+const (
+	// DefaultLimit is the page size if the request doesn't set a limit
+	DefaultLimit = 20
+	// MaxLimit is the largest page size, larger limits are clamped to it
+	MaxLimit = 100
+)
+
+// Offset are the parameters of offset based pagination
+type Offset struct {
+	Limit  int
+	Offset int
+}
+
+// Cursor are the parameters of cursor based pagination, Cursor is empty for
+// the first page
+type Cursor struct {
+	Limit  int
+	Cursor string
+}
+
+// Envelope wraps a page of items. Total is set by offset based and NextCursor
+// by cursor based endpoints, it is empty on the last page.
+type Envelope struct {
+	Items      interface{} `json:"items"`
+	Total      *int        `json:"total,omitempty"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// NewOffsetEnvelope returns the envelope of an offset based page
+func NewOffsetEnvelope(items interface{}, total int) Envelope {
+	return Envelope{Items: items, Total: &total}
+}
+
+// NewCursorEnvelope returns the envelope of a cursor based page
+func NewCursorEnvelope(items interface{}, nextCursor string) Envelope {
+	return Envelope{Items: items, NextCursor: nextCursor}
+}
+
+// ParseOffset parses the limit and offset query parameters. A missing limit
+// defaults to DefaultLimit and one above MaxLimit is clamped to it.
+func ParseOffset(r *http.Request) (Offset, error) {
+	limit, err := parseLimit(r)
+	if err != nil {
+		return Offset{}, err
+	}
+
+	offset := 0
+	if value := r.URL.Query().Get("offset"); value != "" {
+		offset, err = strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return Offset{}, fmt.Errorf("invalid offset %q: must be a non-negative integer", value)
+		}
+	}
+	return Offset{Limit: limit, Offset: offset}, nil
+}
+
+// ParseCursor parses the limit and cursor query parameters. A missing limit
+// defaults to DefaultLimit and one above MaxLimit is clamped to it.
+func ParseCursor(r *http.Request) (Cursor, error) {
+	limit, err := parseLimit(r)
+	if err != nil {
+		return Cursor{}, err
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+	if cursor != "" {
+		if _, err := DecodeCursor(cursor); err != nil {
+			return Cursor{}, fmt.Errorf("invalid cursor %q", cursor)
+		}
+	}
+	return Cursor{Limit: limit, Cursor: cursor}, nil
+}
+
+// OffsetFromRequest parses the offset parameters like ParseOffset. Invalid
+// parameters are logged at warn level and answered with 400, the handler
+// should return if ok is false.
+func OffsetFromRequest(w http.ResponseWriter, r *http.Request, l log.Logger) (Offset, bool) {
+	page, err := ParseOffset(r)
+	if err != nil {
+		reject(w, r, l, err)
+		return Offset{}, false
+	}
+	return page, true
+}
+
+// CursorFromRequest parses the cursor parameters like ParseCursor. Invalid
+// parameters are logged at warn level and answered with 400, the handler
+// should return if ok is false.
+func CursorFromRequest(w http.ResponseWriter, r *http.Request, l log.Logger) (Cursor, bool) {
+	page, err := ParseCursor(r)
+	if err != nil {
+		reject(w, r, l, err)
+		return Cursor{}, false
+	}
+	return page, true
+}
+
+// EncodeCursor returns an opaque cursor for the position, e.g. the id of the
+// last item on the page
+func EncodeCursor(position string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(position))
+}
+
+// DecodeCursor returns the position encoded by EncodeCursor
+func DecodeCursor(cursor string) (string, error) {
+	position, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", err
+	}
+	return string(position), nil
+}
+
+func parseLimit(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return DefaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("invalid limit %q: must be a positive integer", value)
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	return limit, nil
+}
+
+func reject(w http.ResponseWriter, r *http.Request, l log.Logger, err error) {
+	l.WithContext(r.Context()).WithError(err).WithFields(log.Fields{
+		"method": r.Method,
+		"path":   r.URL.Path,
+	}).Warn("invalid pagination parameters")
+	response.Error(w, l, http.StatusBadRequest, err.Error())
+}
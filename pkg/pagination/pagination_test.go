@@ -0,0 +1,99 @@
+package pagination
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+func TestParseOffset(t *testing.T) {
+	tests := []struct {
+		query string
+		want  Offset
+	}{
+		{"", Offset{Limit: DefaultLimit}},
+		{"limit=5&offset=10", Offset{Limit: 5, Offset: 10}},
+		{"limit=1000", Offset{Limit: MaxLimit}},
+		{"offset=0", Offset{Limit: DefaultLimit}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := ParseOffset(httptest.NewRequest(http.MethodGet, "/users?"+tt.query, nil))
+			if err != nil {
+				t.Fatalf("ParseOffset failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseOffset = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOffsetInvalid(t *testing.T) {
+	for _, query := range []string{"limit=0", "limit=-1", "limit=ten", "offset=-5", "offset=x"} {
+		if _, err := ParseOffset(httptest.NewRequest(http.MethodGet, "/users?"+query, nil)); err == nil {
+			t.Errorf("ParseOffset(%q) succeeded, want an error", query)
+		}
+	}
+}
+
+func TestParseCursor(t *testing.T) {
+	next := EncodeCursor("user-42")
+	got, err := ParseCursor(httptest.NewRequest(http.MethodGet, "/users?limit=500&cursor="+next, nil))
+	if err != nil {
+		t.Fatalf("ParseCursor failed: %v", err)
+	}
+	if got.Limit != MaxLimit || got.Cursor != next {
+		t.Errorf("ParseCursor = %+v, want the clamped limit and the cursor", got)
+	}
+	if position, err := DecodeCursor(got.Cursor); err != nil || position != "user-42" {
+		t.Errorf("DecodeCursor = %q, %v, want the encoded position", position, err)
+	}
+
+	for _, query := range []string{"cursor=%25%25%25", "cursor=a+b", "limit=abc"} {
+		if _, err := ParseCursor(httptest.NewRequest(http.MethodGet, "/users?"+query, nil)); err == nil {
+			t.Errorf("ParseCursor(%q) succeeded, want an error", query)
+		}
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	tests := []struct {
+		name     string
+		envelope Envelope
+		want     string
+	}{
+		{"offset", NewOffsetEnvelope([]string{"a"}, 0), `{"items":["a"],"total":0}`},
+		{"cursor", NewCursorEnvelope([]string{"a"}, EncodeCursor("a")), `{"items":["a"],"next_cursor":"YQ"}`},
+		{"last cursor page", NewCursorEnvelope([]string{}, ""), `{"items":[]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.envelope)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("envelope = %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestOffsetFromRequestRejects(t *testing.T) {
+	l, logs := log.NewObserver()
+	rec := httptest.NewRecorder()
+
+	if _, ok := OffsetFromRequest(rec, httptest.NewRequest(http.MethodGet, "/users?limit=x", nil), l); ok {
+		t.Fatal("OffsetFromRequest accepted an invalid limit")
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rec.Code)
+	}
+	if len(logs.FilterLevel(log.LevelWarn)) != 1 {
+		t.Errorf("want one warning, got %v", logs.All())
+	}
+}
@@ -0,0 +1,150 @@
+// Package request decodes and validates request bodies
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// DefaultMaxBodyBytes is the largest body Bind accepts
+const DefaultMaxBodyBytes = 1 << 20
+
+// Validator is implemented by request bodies which check their own values,
+// Bind calls Validate after decoding
+type Validator interface {
+	Validate() error
+}
+
+// BindError is returned by Bind, its message is meant for the client
+type BindError struct {
+	// Status is the status code to respond with, 400, 413 or 415.
+	Status int
+	Msg    string
+	Err    error
+}
+
+func (e *BindError) Error() string {
+	return e.Msg
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// Bind decodes the JSON body of r into v with BindLimit and DefaultMaxBodyBytes
+func Bind(r *http.Request, v interface{}) error {
+	return BindLimit(r, v, DefaultMaxBodyBytes)
+}
+
+// BindLimit decodes the JSON body of r into v. The request must have a JSON
+// content type, the body must not be larger than maxBytes and must hold a
+// single JSON value without unknown fields. If v implements Validator it is
+// validated afterwards. A body limited by middleware.MaxBodyBytes to less
+// than maxBytes fails with a 413 error as well. Errors are of type *BindError
+// and malformed bodies are logged at debug level with the logger of the
+// request context.
+func BindLimit(r *http.Request, v interface{}, maxBytes int64) error {
+	err := bind(r, v, maxBytes)
+	if err != nil {
+		log.FromContext(r.Context()).WithContext(r.Context()).WithError(err).WithFields(log.Fields{
+			"method": r.Method,
+			"path":   r.URL.Path,
+		}).Debug("failed to bind request body")
+	}
+	return err
+}
+
+func bind(r *http.Request, v interface{}, maxBytes int64) error {
+	if !isJSON(r.Header.Get("Content-Type")) {
+		return &BindError{Status: http.StatusUnsupportedMediaType, Msg: "content type must be application/json"}
+	}
+	// requests built by clients and tests may have no body at all
+	if r.Body == nil || r.Body == http.NoBody {
+		return &BindError{Status: http.StatusBadRequest, Msg: decodeMessage(io.EOF), Err: io.EOF}
+	}
+
+	counter := &countingReader{r: r.Body}
+	dec := json.NewDecoder(io.LimitReader(counter, maxBytes+1))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return &BindError{Status: http.StatusRequestEntityTooLarge, Msg: fmt.Sprintf("request body must not be larger than %d bytes", maxErr.Limit), Err: err}
+		}
+		if counter.n > maxBytes {
+			return &BindError{Status: http.StatusRequestEntityTooLarge, Msg: fmt.Sprintf("request body must not be larger than %d bytes", maxBytes), Err: err}
+		}
+		return &BindError{Status: http.StatusBadRequest, Msg: decodeMessage(err), Err: err}
+	}
+	trailing := dec.More() || !atEOF(dec)
+	if counter.n > maxBytes {
+		return &BindError{Status: http.StatusRequestEntityTooLarge, Msg: fmt.Sprintf("request body must not be larger than %d bytes", maxBytes)}
+	}
+	if trailing {
+		return &BindError{Status: http.StatusBadRequest, Msg: "request body must only contain a single JSON value"}
+	}
+
+	if validator, ok := v.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return &BindError{Status: http.StatusBadRequest, Msg: err.Error(), Err: err}
+		}
+	}
+	return nil
+}
+
+// decodeMessage describes a decoding error for the client
+func decodeMessage(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return "request body must not be empty"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "request body contains malformed JSON"
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("request body contains malformed JSON at position %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		if typeErr.Field != "" {
+			return fmt.Sprintf("request body contains an invalid value for field %q, expected %s", typeErr.Field, typeErr.Type)
+		}
+		return fmt.Sprintf("request body contains an invalid value at position %d", typeErr.Offset)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return "request body contains unknown field " + strings.TrimPrefix(err.Error(), "json: unknown field ")
+	}
+	return "request body is invalid: " + err.Error()
+}
+
+// isJSON reports whether the content type is application/json or a +json type
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// atEOF reports whether the decoder has nothing but whitespace left
+func atEOF(dec *json.Decoder) bool {
+	_, err := dec.Token()
+	return errors.Is(err, io.EOF)
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.n += int64(n)
+	return n, err
+}
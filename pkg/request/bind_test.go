@@ -0,0 +1,112 @@
+package request
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// user request body with validation
+type user struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func (u *user) Validate() error {
+	if u.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func newRequest(body, contentType string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	return r
+}
+
+func TestBind(t *testing.T) {
+	var u user
+	if err := Bind(newRequest(`{"name":"alice","age":30}`, "application/json; charset=utf-8"), &u); err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	if u.Name != "alice" || u.Age != 30 {
+		t.Errorf("decoded %+v", u)
+	}
+
+	if err := Bind(newRequest(`{"name":"bob"}`, "application/merge-patch+json"), &u); err != nil {
+		t.Errorf("Bind of a +json content type failed: %v", err)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *http.Request
+		status  int
+		message string
+	}{
+		{"wrong content type", newRequest(`{"name":"alice"}`, "text/plain"), http.StatusUnsupportedMediaType, "content type must be application/json"},
+		{"missing content type", newRequest(`{"name":"alice"}`, ""), http.StatusUnsupportedMediaType, "content type must be application/json"},
+		{"unknown field", newRequest(`{"name":"alice","admin":true}`, "application/json"), http.StatusBadRequest, `unknown field "admin"`},
+		{"trailing data", newRequest(`{"name":"alice"}{"name":"bob"}`, "application/json"), http.StatusBadRequest, "single JSON value"},
+		{"trailing garbage", newRequest(`{"name":"alice"} x`, "application/json"), http.StatusBadRequest, "single JSON value"},
+		{"malformed", newRequest(`{"name":`, "application/json"), http.StatusBadRequest, "malformed JSON"},
+		{"wrong type", newRequest(`{"age":"old"}`, "application/json"), http.StatusBadRequest, `invalid value for field "age"`},
+		{"empty", newRequest(``, "application/json"), http.StatusBadRequest, "must not be empty"},
+		{"oversize", newRequest(`{"name":"`+strings.Repeat("a", DefaultMaxBodyBytes)+`"}`, "application/json"), http.StatusRequestEntityTooLarge, "must not be larger than"},
+		{"invalid", newRequest(`{"age":3}`, "application/json"), http.StatusBadRequest, "name is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var u user
+			err := Bind(tt.req, &u)
+
+			var bindErr *BindError
+			if !errors.As(err, &bindErr) {
+				t.Fatalf("Bind returned %v, want a *BindError", err)
+			}
+			if bindErr.Status != tt.status {
+				t.Errorf("status = %d, want %d", bindErr.Status, tt.status)
+			}
+			if !strings.Contains(bindErr.Error(), tt.message) {
+				t.Errorf("message = %q, want it to contain %q", bindErr.Error(), tt.message)
+			}
+		})
+	}
+}
+
+func TestBindWithoutBody(t *testing.T) {
+	for _, body := range []io.ReadCloser{nil, http.NoBody} {
+		r := httptest.NewRequest(http.MethodPost, "/users", nil)
+		r.Header.Set("Content-Type", "application/json")
+		r.Body = body
+
+		var bindErr *BindError
+		if err := Bind(r, &user{}); !errors.As(err, &bindErr) || bindErr.Status != http.StatusBadRequest {
+			t.Errorf("Bind with body %v returned %v, want a 400 error", body, err)
+		}
+	}
+}
+
+func TestBindLimit(t *testing.T) {
+	body := `{"name":"alice"}`
+
+	var bindErr *BindError
+	if err := BindLimit(newRequest(body, "application/json"), &user{}, int64(len(body)-1)); !errors.As(err, &bindErr) || bindErr.Status != http.StatusRequestEntityTooLarge {
+		t.Errorf("BindLimit below the body size returned %v, want a 413 error", err)
+	}
+	if err := BindLimit(newRequest(body, "application/json"), &user{}, int64(len(body))); err != nil {
+		t.Errorf("BindLimit of the body size failed: %v", err)
+	}
+
+	// a lower limit set by middleware.MaxBodyBytes wins
+	r := newRequest(body, "application/json")
+	r.Body = http.MaxBytesReader(httptest.NewRecorder(), r.Body, 4)
+	if err := Bind(r, &user{}); !errors.As(err, &bindErr) || bindErr.Status != http.StatusRequestEntityTooLarge {
+		t.Errorf("Bind of a MaxBytesReader body returned %v, want a 413 error", err)
+	}
+}
@@ -0,0 +1,74 @@
+package response
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// ETag returns a strong entity tag for the body
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// WeakETag returns a weak entity tag for the body, for representations which
+// are equivalent but not necessarily byte for byte identical
+func WeakETag(body []byte) string {
+	return "W/" + ETag(body)
+}
+
+// JSONWithETag writes v as JSON like JSON and tags it with a strong ETag. If
+// the If-None-Match header of a GET or HEAD request matches the tag, 304 Not
+// Modified is written without body instead and logged at debug level.
+func JSONWithETag(w http.ResponseWriter, l log.Logger, r *http.Request, status int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil || status < 200 || status >= 300 {
+		JSON(w, l, status, v)
+		return
+	}
+	body = append(body, '\n')
+
+	tag := ETag(body)
+	w.Header().Set("ETag", tag)
+	if (r.Method == http.MethodGet || r.Method == http.MethodHead) && NoneMatch(r, tag) {
+		l.WithContext(r.Context()).WithFields(log.Fields{
+			"method": r.Method,
+			"path":   r.URL.Path,
+			"etag":   tag,
+		}).Debug("not modified")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(body); err != nil {
+		l.WithError(err).WithFields(log.Fields{"status": status}).Error("failed to write response body")
+	}
+}
+
+// NoneMatch reports whether the If-None-Match header of the request matches
+// the entity tag, using the weak comparison RFC 7232 requires for it. "*"
+// matches any tag.
+func NoneMatch(r *http.Request, tag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+func TestJSONWithETag(t *testing.T) {
+	l, _ := log.NewObserver()
+	v := map[string]string{"id": "42"}
+
+	rec := httptest.NewRecorder()
+	JSONWithETag(rec, l, httptest.NewRequest(http.MethodGet, "/users/42", nil), http.StatusOK, v)
+
+	tag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || tag == "" {
+		t.Fatalf("status = %d with ETag %q, want 200 with a tag", rec.Code, tag)
+	}
+	if tag != ETag(rec.Body.Bytes()) {
+		t.Errorf("ETag %s doesn't match the body", tag)
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		ifNoneMatch string
+		status      int
+	}{
+		{"matching tag", http.MethodGet, tag, http.StatusNotModified},
+		{"matching weak tag in list", http.MethodHead, `"other", W/` + tag, http.StatusNotModified},
+		{"any tag", http.MethodGet, "*", http.StatusNotModified},
+		{"other tag", http.MethodGet, `"other"`, http.StatusOK},
+		{"unsafe method", http.MethodPut, tag, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, logs := log.NewObserver()
+			req := httptest.NewRequest(tt.method, "/users/42", nil)
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			rec := httptest.NewRecorder()
+
+			JSONWithETag(rec, l, req, http.StatusOK, v)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("ETag"); got != tag {
+				t.Errorf("ETag = %q, want %q", got, tag)
+			}
+			if tt.status == http.StatusNotModified {
+				if rec.Body.Len() != 0 {
+					t.Errorf("304 has a body: %q", rec.Body.String())
+				}
+				if len(logs.FilterLevel(log.LevelDebug)) != 1 {
+					t.Errorf("want the 304 logged at debug level, got %v", logs.All())
+				}
+			}
+		})
+	}
+}
+
+func TestJSONWithETagError(t *testing.T) {
+	l, _ := log.NewObserver()
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("If-None-Match", "*")
+	rec := httptest.NewRecorder()
+
+	JSONWithETag(rec, l, req, http.StatusNotFound, ErrorBody{Error: "user not found"})
+
+	if rec.Code != http.StatusNotFound || rec.Header().Get("ETag") != "" {
+		t.Errorf("status = %d with ETag %q, want an untagged 404", rec.Code, rec.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,61 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// ProblemContentType is the content type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 problem details body
+type ProblemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// RequestID extension member correlating the problem with the logs.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Problem writes an RFC 7807 problem details response for the request and logs
+// it, server errors at error level and client errors at warn level. The
+// request id set by middleware.RequestID is included as request_id.
+func Problem(w http.ResponseWriter, l log.Logger, r *http.Request, status int, detail string) {
+	problem := ProblemDetails{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: log.RequestIDFromContext(r.Context()),
+	}
+
+	logger := l.WithContext(r.Context()).WithFields(log.Fields{
+		"status": status,
+		"method": r.Method,
+		"path":   r.URL.Path,
+	})
+	switch {
+	case status >= http.StatusInternalServerError:
+		logger.Errorf("%s: %s", problem.Title, detail)
+	case status >= http.StatusBadRequest:
+		logger.Warnf("%s: %s", problem.Title, detail)
+	default:
+		logger.Infof("%s: %s", problem.Title, detail)
+	}
+
+	body, err := json.Marshal(problem)
+	if err != nil {
+		logger.WithError(err).Error("failed to encode problem details")
+		return
+	}
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(status)
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		logger.WithError(err).Error("failed to write problem details")
+	}
+}
@@ -0,0 +1,56 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+func TestProblem(t *testing.T) {
+	tests := []struct {
+		status int
+		level  log.Level
+	}{
+		{http.StatusInternalServerError, log.LevelError},
+		{http.StatusNotFound, log.LevelWarn},
+	}
+	for _, tt := range tests {
+		l, logs := log.NewObserver()
+		req := httptest.NewRequest(http.MethodGet, "/api/users/42", nil)
+		req = req.WithContext(context.WithValue(req.Context(), log.RequestIDKey, "req-1"))
+		rec := httptest.NewRecorder()
+
+		Problem(rec, l, req, tt.status, "something happened")
+
+		if rec.Code != tt.status {
+			t.Errorf("status = %d, want %d", rec.Code, tt.status)
+		}
+		if got := rec.Header().Get("Content-Type"); got != ProblemContentType {
+			t.Errorf("content type = %q, want %s", got, ProblemContentType)
+		}
+		var problem ProblemDetails
+		if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("body isn't JSON: %q: %v", rec.Body.String(), err)
+		}
+		want := ProblemDetails{
+			Type:      "about:blank",
+			Title:     http.StatusText(tt.status),
+			Status:    tt.status,
+			Detail:    "something happened",
+			Instance:  "/api/users/42",
+			RequestID: "req-1",
+		}
+		if problem != want {
+			t.Errorf("problem = %+v, want %+v", problem, want)
+		}
+
+		entries := logs.All()
+		if len(entries) != 1 || entries[0].Level != tt.level {
+			t.Errorf("status %d: logged %+v, want one %s entry", tt.status, entries, tt.level)
+		}
+	}
+}
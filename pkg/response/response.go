@@ -0,0 +1,39 @@
+// Package response writes JSON responses and logs failures which handlers
+// usually ignore
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// ErrorBody is the JSON envelope written by Error
+type ErrorBody struct {
+	Error string `json:"error"`
+}
+
+// internalError is written if the body can't be encoded
+var internalError = []byte(`{"error":"internal server error"}`)
+
+// JSON writes v as JSON body with the status. If v can't be encoded a 500 is
+// written instead, failures are logged at error level.
+func JSON(w http.ResponseWriter, l log.Logger, status int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		l.WithError(err).WithFields(log.Fields{"status": status}).Error("failed to encode response body")
+		status, body = http.StatusInternalServerError, internalError
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		l.WithError(err).WithFields(log.Fields{"status": status}).Error("failed to write response body")
+	}
+}
+
+// Error writes the message in the standard error envelope {"error": msg}
+func Error(w http.ResponseWriter, l log.Logger, status int, msg string) {
+	JSON(w, l, status, ErrorBody{Error: msg})
+}
@@ -0,0 +1,85 @@
+package response
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+func TestJSON(t *testing.T) {
+	l, logs := log.NewObserver()
+	rec := httptest.NewRecorder()
+
+	JSON(rec, l, http.StatusCreated, map[string]string{"id": "42"})
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status = %d, want 201", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("content type = %q, want application/json", got)
+	}
+	if got := rec.Body.String(); got != "{\"id\":\"42\"}\n" {
+		t.Errorf("body = %q", got)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("a successful response was logged: %v", logs.All())
+	}
+}
+
+func TestJSONEncodeFailure(t *testing.T) {
+	l, logs := log.NewObserver()
+	rec := httptest.NewRecorder()
+
+	JSON(rec, l, http.StatusOK, map[string]interface{}{"updates": make(chan int)})
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+	if got := rec.Body.String(); got != string(internalError)+"\n" {
+		t.Errorf("body = %q, want the internal error envelope", got)
+	}
+	entries := logs.FilterLevel(log.LevelError)
+	if len(entries) != 1 || entries[0].Message != "failed to encode response body" {
+		t.Fatalf("want the encode failure logged at error level, got %v", logs.All())
+	}
+	if entries[0].Fields["status"] != http.StatusOK {
+		t.Errorf("status field = %v, want the intended status 200", entries[0].Fields["status"])
+	}
+}
+
+// failingWriter is a ResponseWriter whose body writes fail
+type failingWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestJSONWriteFailure(t *testing.T) {
+	l, logs := log.NewObserver()
+
+	JSON(failingWriter{httptest.NewRecorder()}, l, http.StatusOK, "ok")
+
+	entries := logs.FilterLevel(log.LevelError)
+	if len(entries) != 1 || entries[0].Message != "failed to write response body" {
+		t.Errorf("want the write failure logged, got %v", logs.All())
+	}
+}
+
+func TestError(t *testing.T) {
+	l, _ := log.NewObserver()
+	rec := httptest.NewRecorder()
+
+	Error(rec, l, http.StatusNotFound, "user not found")
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+	if got := rec.Body.String(); got != "{\"error\":\"user not found\"}\n" {
+		t.Errorf("body = %q", got)
+	}
+}
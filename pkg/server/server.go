@@ -0,0 +1,108 @@
+// Package server runs http servers with a graceful shutdown
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// DefaultDrainTimeout is how long in-flight requests may take to complete on
+// shutdown if no other timeout is set
+const DefaultDrainTimeout = 30 * time.Second
+
+// Option configures how a server is run
+type Option func(*config)
+
+type config struct {
+	drainTimeout time.Duration
+	signals      []os.Signal
+}
+
+// WithDrainTimeout sets how long in-flight requests may take to complete on
+// shutdown before their connections are closed
+func WithDrainTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.drainTimeout = d
+	}
+}
+
+// WithSignals sets the signals which shut the server down, SIGINT and SIGTERM
+// by default. No signals are handled if called without any.
+func WithSignals(signals ...os.Signal) Option {
+	return func(c *config) {
+		c.signals = signals
+	}
+}
+
+// RunWithGracefulShutdown serves h on addr until ctx is canceled or the process
+// receives a shutdown signal, then waits for in-flight requests to complete
+func RunWithGracefulShutdown(ctx context.Context, addr string, h http.Handler, l log.Logger, opts ...Option) error {
+	return Serve(ctx, &http.Server{Addr: addr, Handler: h}, l, opts...)
+}
+
+// Serve runs srv until ctx is canceled or the process receives a shutdown
+// signal, then shuts it down gracefully. Listening, draining and stopping are
+// logged at info level. It returns the error of the listener or of the
+// shutdown, nil after a clean shutdown.
+func Serve(ctx context.Context, srv *http.Server, l log.Logger, opts ...Option) error {
+	cfg := &config{
+		drainTimeout: DefaultDrainTimeout,
+		signals:      []os.Signal{os.Interrupt, syscall.SIGTERM},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if len(cfg.signals) > 0 {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, cfg.signals...)
+		defer stop()
+	}
+
+	addr := srv.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		l.Errorf("failed to start http server: %v", err)
+		return err
+	}
+	l.WithFields(log.Fields{"addr": listener.Addr().String()}).Info("http server listening")
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		l.Errorf("http server failed: %v", err)
+		return err
+	case <-ctx.Done():
+	}
+
+	l.WithFields(log.Fields{"timeout": cfg.drainTimeout}).Info("http server draining connections")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.drainTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		l.Errorf("failed to shut down http server gracefully: %v", err)
+		_ = srv.Close()
+		return err
+	}
+	if err := <-errs; err != nil && err != http.ErrServerClosed {
+		l.Errorf("http server failed: %v", err)
+		return err
+	}
+
+	l.Info("http server stopped")
+	return nil
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bitcubix/golang-rest-api/pkg/log"
+)
+
+// listening waits until the server logged its address
+func listening(t *testing.T, logs *log.ObservedLogs) string {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, e := range logs.All() {
+			if addr, ok := e.Fields["addr"].(string); ok {
+				return addr
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("server didn't start listening")
+	return ""
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	l, logs := log.NewObserver()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- RunWithGracefulShutdown(ctx, "127.0.0.1:0", h, l, WithSignals(), WithDrainTimeout(5*time.Second))
+	}()
+	base := "http://" + listening(t, logs)
+
+	resp, err := http.Get(base + "/")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Fatalf("got %d %q, want 200 ok", resp.StatusCode, body)
+	}
+
+	// a request in flight during the shutdown is completed
+	slow := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(base + "/slow")
+		if err == nil {
+			resp.Body.Close()
+		}
+		slow <- err
+	}()
+	<-started
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	if err := <-slow; err != nil {
+		t.Errorf("in-flight request failed during shutdown: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("RunWithGracefulShutdown returned %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server didn't shut down")
+	}
+
+	var msgs []string
+	for _, e := range logs.FilterLevel(log.LevelInfo) {
+		msgs = append(msgs, e.Message)
+	}
+	want := []string{"http server listening", "http server draining connections", "http server stopped"}
+	if len(msgs) != len(want) {
+		t.Fatalf("logged %q, want %q", msgs, want)
+	}
+	for i := range want {
+		if msgs[i] != want[i] {
+			t.Errorf("entry %d = %q, want %q", i, msgs[i], want[i])
+		}
+	}
+}
+
+func TestListenError(t *testing.T) {
+	l, logs := log.NewObserver()
+
+	err := RunWithGracefulShutdown(context.Background(), "127.0.0.1:-1", http.NotFoundHandler(), l, WithSignals())
+	if err == nil {
+		t.Fatal("RunWithGracefulShutdown succeeded with an invalid address")
+	}
+	if len(logs.FilterLevel(log.LevelError)) != 1 {
+		t.Errorf("want the error to be logged, got %+v", logs.All())
+	}
+}